@@ -0,0 +1,58 @@
+package cache
+
+// adaptive holds what ARC and CAR have in common: the capacity c, the target
+// size p of t1 and the ghost lists b1 and b2, which only remember the keys
+// recently evicted from t1 and t2 respectively.
+type adaptive[K comparable] struct {
+	b1, b2 *lru[K, struct{}]
+	p      int
+	c      int
+}
+
+// The ghost lists are never allowed to overflow on their own, the policies
+// decide which keys to drop.
+func newAdaptive[K comparable](size int) adaptive[K] {
+	return adaptive[K]{
+		c:  size,
+		b1: newLRU[K, struct{}](size),
+		b2: newLRU[K, struct{}](2 * size),
+	}
+}
+
+// forget drops the key from the ghost lists. It returns false if they didn't remember it.
+func (a *adaptive[K]) forget(key K) bool {
+	return a.b1.Delete(key) || a.b2.Delete(key)
+}
+
+func (a *adaptive[K]) reset() {
+	a.b1.Purge()
+	a.b2.Purge()
+	a.p = 0
+}
+
+// rescale changes the capacity and scales p proportionally. It returns how
+// many keys each ghost list keeps at the new scale, see trim.
+func (a *adaptive[K]) rescale(size int) (b1Size, b2Size int) {
+	b1Size, b2Size = a.b1.Len(), a.b2.Len()
+	if a.c > 0 {
+		a.p = a.p * size / a.c
+		b1Size, b2Size = b1Size*size/a.c, b2Size*size/a.c
+	}
+	a.c = size
+	a.b1.size, a.b2.size = size, 2*size
+	return b1Size, b2Size
+}
+
+// trim drops the least recently used keys of the ghost lists until they are
+// back to the sizes returned by rescale and, given the lengths of t1 and t2,
+// |t1|+|b1| <= c and |t1|+|b1|+|t2|+|b2| <= 2c hold again.
+func (a *adaptive[K]) trim(b1Size, b2Size, t1Len, t2Len int) {
+	b1Size = min(b1Size, a.c-t1Len)
+	for a.b1.Len() > b1Size {
+		_ = a.b1.remove(a.b1.last.key)
+	}
+	b2Size = min(b2Size, 2*a.c-t1Len-a.b1.Len()-t2Len)
+	for a.b2.Len() > b2Size {
+		_ = a.b2.remove(a.b2.last.key)
+	}
+}
@@ -1,118 +1,162 @@
 package cache
 
-// arc is an adaptation of the ARC algorithm for the Cache interface.
-type arc struct {
-	t1, b1, t2, b2 *lru
-	p              int
-	c              int
+// arc implements Policy
+// It follows the ARC algorithm as described in "ARC: A Self-Tuning, Low
+// Overhead Replacement Cache" by Nimrod Megiddo and Dharmendra S. Modha.
+// t1 holds the keys requested once recently, t2 the ones requested at least
+// twice, and hits in the ghost lists b1 and b2 adapt the target size p of t1.
+type arc[K comparable, V any] struct {
+	evictNotifier[K, V]
+	adaptive[K]
+	t1, t2 *lru[K, V]
 }
 
-func newARC(size int) *arc {
-	c := size
-	t1Size, t2Size, b1Size, b2Size := c/4, (c+1)/4, (c+2)/4, (c+3)/4
-	return &arc{
-		c:  c,
-		p:  0,
-		t1: newLRU(t1Size),
-		t2: newLRU(t2Size),
-		b1: newLRU(b1Size),
-		b2: newLRU(b2Size),
+func newARC[K comparable, V any](size int) *arc[K, V] {
+	return &arc[K, V]{
+		adaptive: newAdaptive[K](size),
+		t1:       newLRU[K, V](size),
+		t2:       newLRU[K, V](size),
 	}
 }
 
-func (a *arc) Read(key int) (value int, isCacheMiss bool) {
-	// Case I:
-	if value, isCacheMiss = a.t2.Read(key); !isCacheMiss {
-		return value, false
-	}
-	if _, isCacheMiss = a.t1.Read(key); !isCacheMiss {
-		node := a.t1.remove(key)
-		if _, evicted := a.t2.write(node.key, node.value); evicted != nil {
-			a.b2.Write(evicted.key, evicted.value)
-		}
+func (a *arc[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	// Case I: cache hit, the key moves to the head of t2.
+	if node := a.t1.remove(key); node != nil {
+		a.t2.insert(node.key, node.value)
 		return node.value, false
 	}
-	// Case II:
-	if _, isCacheMiss = a.b1.Read(key); !isCacheMiss {
-		b1Size, b2Size := len(a.b1.hash), len(a.b2.hash)
-		a.p = min(a.c, a.p + max(b2Size / b1Size, 1))
-		a.replace(key)
-		node := a.b1.remove(key)
-		if _, evicted := a.t2.write(node.key, node.value); evicted != nil {
-			a.b2.Write(evicted.key, evicted.value)
-		}
+	if node := a.t2.read(key); node != nil {
 		return node.value, false
 	}
-	// Case III:
-	if _, isCacheMiss = a.b2.Read(key); !isCacheMiss {
-		b1Size, b2Size := len(a.b1.hash), len(a.b2.hash)
+	return value, true
+}
+
+func (a *arc[K, V]) Write(key K, value V) {
+	// Case I: cache hit, update the value and move the key to the head of t2.
+	if node := a.t1.remove(key); node != nil {
+		a.t2.insert(key, value)
+		return
+	}
+	if node := a.t2.read(key); node != nil {
+		node.value = value
+		return
+	}
+	// Case II: ghost hit in b1, favour recency by increasing t1's target size.
+	if a.b1.remove(key) != nil {
+		b1Size, b2Size := a.b1.Len()+1, a.b2.Len()
+		a.p = min(a.c, a.p+max(b2Size/b1Size, 1))
+		a.replace(false)
+		a.t2.insert(key, value)
+		return
+	}
+	// Case III: ghost hit in b2, favour frequency by decreasing t1's target size.
+	if a.b2.remove(key) != nil {
+		b1Size, b2Size := a.b1.Len(), a.b2.Len()+1
 		a.p = max(0, a.p-max(b1Size/b2Size, 1))
-		a.replace(key)
-		node := a.b2.remove(key)
-		if _, evicted := a.t2.write(node.key, node.value); evicted != nil {
-			a.b2.Write(evicted.key, evicted.value)
-		}
-		return node.value, false
+		a.replace(true)
+		a.t2.insert(key, value)
+		return
 	}
-	// Case IV:
-	t1Size := len(a.t1.hash)
-	l1Size, l2Size := len(a.t1.hash)+len(a.b1.hash), len(a.t2.hash)+len(a.b2.hash)
+	// Case IV: complete miss.
+	l1Size := a.t1.Len() + a.b1.Len()
+	l2Size := a.t2.Len() + a.b2.Len()
 	if l1Size == a.c {
-		if t1Size < a.c {
+		if a.t1.Len() < a.c {
 			_ = a.b1.remove(a.b1.last.key)
-			a.replace(key)
+			a.replace(false)
 		} else {
 			node := a.t1.remove(a.t1.last.key)
-			a.b1.Write(node.key, node.value)
+			a.notifyEvict(node.key, node.value)
 		}
-	}
-	if l1Size < a.c && l1Size+l2Size >= a.c {
-		if l1Size+l2Size == 2*a.c {
-			a.b2.remove(a.b2.last.key)
+	} else if l1Size < a.c && l1Size+l2Size >= a.c {
+		if l1Size+l2Size >= 2*a.c {
+			_ = a.b2.remove(a.b2.last.key)
 		}
-		a.replace(key)
+		a.replace(false)
 	}
-	return 0, true
+	a.t1.insert(key, value)
 }
 
-func (a *arc) Write(key, value int) {
-	// if it exists in t2, update value and promote to the head of the LRU.
-	if node := a.t2.read(key); node != nil {
-		node.value = value
-		return
+// Peek only looks into t1 and t2, the ghost lists b1 and b2 only remember evicted keys.
+func (a *arc[K, V]) Peek(key K) (value V, found bool) {
+	if value, found = a.t1.Peek(key); found {
+		return value, true
 	}
-	// if it exists in t1, remove it from t1, add it to t2, handle eviction from t2 into b2.
-	if node := a.t1.read(key); node != nil {
-		a.t1.remove(key)
-		if _, evicted := a.t2.write(key, value); evicted != nil {
-			a.b2.Write(evicted.key, evicted.value)
-		}
+	return a.t2.Peek(key)
+}
+
+func (a *arc[K, V]) Delete(key K) bool {
+	if a.forget(key) {
+		return false
+	}
+	return a.t1.Delete(key) || a.t2.Delete(key)
+}
+
+func (a *arc[K, V]) Len() int {
+	return a.t1.Len() + a.t2.Len()
+}
+
+func (a *arc[K, V]) Capacity() int {
+	return a.c
+}
+
+func (a *arc[K, V]) Keys() []K {
+	return append(a.t1.Keys(), a.t2.Keys()...)
+}
+
+func (a *arc[K, V]) Purge() {
+	a.t1.Purge()
+	a.t2.Purge()
+	a.reset()
+}
+
+// Resize scales p and the ghost lists proportionally to the new size.
+// When shrinking, keys are replaced from t1 and t2 as usual until they fit,
+// then the ghost lists drop their least recently used keys until they are
+// back to scale and the size invariants hold again.
+func (a *arc[K, V]) Resize(size int) {
+	b1Size, b2Size := a.rescale(size)
+	a.t1.size, a.t2.size = size, size
+	for a.Len() > a.c {
+		a.replace(false)
+	}
+	a.trim(b1Size, b2Size, a.t1.Len(), a.t2.Len())
+}
+
+// replace makes room for a new key by moving the least recently used key of
+// either t1 or t2 into its ghost list, based on the target size p. The value
+// is evicted, only the key is remembered.
+// inB2 tells whether the requested key was found in b2.
+// Nothing is replaced while there is still room in the cache, which can
+// happen after keys are deleted.
+func (a *arc[K, V]) replace(inB2 bool) {
+	if a.t1.Len()+a.t2.Len() < a.c {
 		return
 	}
-	// if it doesn't exist in t1 or t2, insert it in t1, handle eviction from t1 into b1.
-	if _, evicted := a.t1.write(key, value); evicted != nil {
-		a.b1.write(evicted.key, evicted.value)
+	t1Size := a.t1.Len()
+	if t1Size >= 1 && ((inB2 && t1Size == a.p) || t1Size > a.p) || a.t2.Len() == 0 {
+		node := a.t1.remove(a.t1.last.key)
+		a.b1.insert(node.key, struct{}{})
+		a.notifyEvict(node.key, node.value)
 		return
 	}
+	node := a.t2.remove(a.t2.last.key)
+	a.b2.insert(node.key, struct{}{})
+	a.notifyEvict(node.key, node.value)
 }
 
-func (a *arc) replace(key int) {
-	t1Size := len(a.t1.hash)
-	_, cacheMiss := a.b2.Read(key)
-	if t1Size >= 1 && ((!cacheMiss && t1Size == a.p) || t1Size > a.p) {
-		if a.t1.last == nil {
-			return
-		}
-		if node := a.t1.remove(a.t1.last.key); node != nil {
-			a.b1.Write(node.key, node.value)
-		}
-	} else {
-		if a.t2.last == nil {
-			return
-		}
-		if node := a.t2.remove(a.t2.last.key); node != nil {
-			a.b2.Write(node.key, node.value)
-		}
+type arcState[K comparable] struct {
+	t1, t2, b1, b2 []K // keys from most to least recently used
+	p              int
+}
+
+func (a *arc[K, V]) state() arcState[K] {
+	return arcState[K]{
+		t1: a.t1.Keys(),
+		t2: a.t2.Keys(),
+		b1: a.b1.Keys(),
+		b2: a.b2.Keys(),
+		p:  a.p,
 	}
 }
 
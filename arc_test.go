@@ -1,33 +1,200 @@
 package cache
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
 func TestARC(t *testing.T) {
-	t.Run("it uses the full capacity of the cache", func(t *testing.T) {
-		c := newARC(8)
+	t.Run("hits move keys to t2 and misses leave the state untouched", func(t *testing.T) {
+		c := newARC[int, int](4)
 		c.Write(1, 10)
 		c.Write(2, 20)
-		c.Write(3, 30)
-		c.Write(4, 40)
 		value, isCacheMiss := c.Read(1)
-		if isCacheMiss != false {
-			t.Fatalf("expected read of key 1 to be a hit because it was stored in b1 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
-		}
-		value, isCacheMiss = c.Read(1)
-		if isCacheMiss == true || value != 10 {
-			t.Fatalf("expected to read value for key 1 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
-		}
-		value, isCacheMiss = c.Read(4)
-		if isCacheMiss == true || value != 40 {
-			t.Fatalf("expected to read value for key 4 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
-		}
-		c.Write(5, 50)
-		c.Write(6, 60)
-		value, isCacheMiss = c.Read(5)
-		if isCacheMiss == true || value != 50 {
-			t.Fatalf("expected to read value for key 5 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		if isCacheMiss || value != 10 {
+			t.Fatalf("expected to read key 1 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+		expected := arcState[int]{t1: []int{2}, t2: []int{1}, b1: []int{}, b2: []int{}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("unexpected state after a hit: %#v", state)
+		}
+		if _, isCacheMiss = c.Read(3); !isCacheMiss {
+			t.Fatal("expected a cache miss for key 3")
+		}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("unexpected state after a miss: %#v", state)
+		}
+		c.Write(2, 21)
+		expected = arcState[int]{t1: []int{}, t2: []int{2, 1}, b1: []int{}, b2: []int{}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("unexpected state after updating a key: %#v", state)
+		}
+		if value, isCacheMiss = c.Read(2); isCacheMiss || value != 21 {
+			t.Fatalf("expected to read the updated value for key 2 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+	})
+	t.Run("replays all the cases of the algorithm", func(t *testing.T) {
+		c := newARC[int, int](4)
+		for idx, step := range []struct {
+			op       string
+			key      int
+			expected arcState[int]
+		}{
+			// Case IV, the cache fills up.
+			{"write", 1, arcState[int]{t1: []int{1}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			{"write", 2, arcState[int]{t1: []int{2, 1}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			{"write", 3, arcState[int]{t1: []int{3, 2, 1}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			{"write", 4, arcState[int]{t1: []int{4, 3, 2, 1}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case IV A, t1 is full so its least recently used key is dropped, not remembered.
+			{"write", 5, arcState[int]{t1: []int{5, 4, 3, 2}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case I, hits move keys to t2.
+			{"read", 3, arcState[int]{t1: []int{5, 4, 2}, t2: []int{3}, b1: []int{}, b2: []int{}, p: 0}},
+			{"read", 4, arcState[int]{t1: []int{5, 2}, t2: []int{4, 3}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case IV B, t1 is over its target size so it's replaced into b1.
+			{"write", 6, arcState[int]{t1: []int{6, 5}, t2: []int{4, 3}, b1: []int{2}, b2: []int{}, p: 0}},
+			{"write", 7, arcState[int]{t1: []int{7, 6}, t2: []int{4, 3}, b1: []int{5, 2}, b2: []int{}, p: 0}},
+			// Case II, hit in b1 grows p.
+			{"write", 2, arcState[int]{t1: []int{7}, t2: []int{2, 4, 3}, b1: []int{6, 5}, b2: []int{}, p: 1}},
+			// Case IV B, t1 is at its target size so t2 is replaced into b2.
+			{"write", 8, arcState[int]{t1: []int{8, 7}, t2: []int{2, 4}, b1: []int{6, 5}, b2: []int{3}, p: 1}},
+			// Case III, hit in b2 shrinks p by |b1|/|b2|.
+			{"write", 3, arcState[int]{t1: []int{8}, t2: []int{3, 2, 4}, b1: []int{7, 6, 5}, b2: []int{}, p: 0}},
+			// Case IV A, t1 and b1 are full so b1 drops its least recently used key.
+			{"write", 9, arcState[int]{t1: []int{9}, t2: []int{3, 2, 4}, b1: []int{8, 7, 6}, b2: []int{}, p: 0}},
+		} {
+			if step.op == "write" {
+				c.Write(step.key, step.key*10)
+			} else if _, isCacheMiss := c.Read(step.key); isCacheMiss {
+				t.Fatalf("step #%d: unexpected cache miss for key %d", idx, step.key)
+			}
+			if state := c.state(); !reflect.DeepEqual(state, step.expected) {
+				t.Fatalf("step #%d: unexpected state %#v", idx, state)
+			}
+		}
+	})
+	t.Run("follows every branch of the paper's pseudocode", func(t *testing.T) {
+		c := newARC[int, int](3)
+		for idx, step := range []struct {
+			op       string
+			key      int
+			expected arcState[int]
+		}{
+			{"write", 5, arcState[int]{t1: []int{5}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			{"write", 4, arcState[int]{t1: []int{4, 5}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			{"write", 1, arcState[int]{t1: []int{1, 4, 5}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case IV A with |T1| = c: the LRU page of T1 is deleted.
+			{"write", 2, arcState[int]{t1: []int{2, 1, 4}, t2: []int{}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case I on T1.
+			{"write", 2, arcState[int]{t1: []int{1, 4}, t2: []int{2}, b1: []int{}, b2: []int{}, p: 0}},
+			// Case IV B with |L1|+|L2| < 2c: REPLACE moves the LRU page of T1 to B1 since |T1| > p.
+			{"write", 3, arcState[int]{t1: []int{3, 1}, t2: []int{2}, b1: []int{4}, b2: []int{}, p: 0}},
+			// Case IV A with |T1| < c: the LRU page of B1 is deleted, then REPLACE.
+			{"write", 5, arcState[int]{t1: []int{5, 3}, t2: []int{2}, b1: []int{1}, b2: []int{}, p: 0}},
+			// Case II with |B1| >= |B2|: p grows by 1.
+			{"write", 1, arcState[int]{t1: []int{5}, t2: []int{1, 2}, b1: []int{3}, b2: []int{}, p: 1}},
+			// Case I on T2.
+			{"read", 1, arcState[int]{t1: []int{5}, t2: []int{1, 2}, b1: []int{3}, b2: []int{}, p: 1}},
+			// Case IV B: REPLACE moves the LRU page of T2 to B2 since |T1| <= p.
+			{"write", 6, arcState[int]{t1: []int{6, 5}, t2: []int{1}, b1: []int{3}, b2: []int{2}, p: 1}},
+			{"read", 6, arcState[int]{t1: []int{5}, t2: []int{6, 1}, b1: []int{3}, b2: []int{2}, p: 1}},
+			{"write", 4, arcState[int]{t1: []int{4, 5}, t2: []int{6}, b1: []int{3}, b2: []int{1, 2}, p: 1}},
+			{"write", 6, arcState[int]{t1: []int{4, 5}, t2: []int{6}, b1: []int{3}, b2: []int{1, 2}, p: 1}},
+			// Case II with |B1| < |B2|: p grows by |B2|/|B1|, up to c.
+			{"write", 3, arcState[int]{t1: []int{4, 5}, t2: []int{3}, b1: []int{}, b2: []int{6, 1, 2}, p: 3}},
+			// Case III with |B2| >= |B1|: p shrinks by 1, then REPLACE moves the
+			// LRU page of T1 to B1 since the key is in B2 and |T1| = p.
+			{"write", 6, arcState[int]{t1: []int{4}, t2: []int{6, 3}, b1: []int{5}, b2: []int{1, 2}, p: 2}},
+			// Case IV B with |L1|+|L2| = 2c: the LRU page of B2 is deleted, then REPLACE.
+			{"write", 7, arcState[int]{t1: []int{7, 4}, t2: []int{6}, b1: []int{5}, b2: []int{3, 1}, p: 2}},
+			{"write", 1, arcState[int]{t1: []int{7}, t2: []int{1, 6}, b1: []int{4, 5}, b2: []int{3}, p: 1}},
+			// Case III with |B2| < |B1|: p shrinks by |B1|/|B2|, down to 0.
+			{"write", 3, arcState[int]{t1: []int{}, t2: []int{3, 1, 6}, b1: []int{7, 4, 5}, b2: []int{}, p: 0}},
+		} {
+			if step.op == "write" {
+				c.Write(step.key, step.key*10)
+			} else if _, isCacheMiss := c.Read(step.key); isCacheMiss {
+				t.Fatalf("step #%d: unexpected cache miss for key %d", idx, step.key)
+			}
+			if state := c.state(); !reflect.DeepEqual(state, step.expected) {
+				t.Fatalf("step #%d: unexpected state %#v", idx, state)
+			}
+		}
+	})
+	t.Run("ghost keys are cache misses", func(t *testing.T) {
+		c := newARC[int, int](2)
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		c.Write(2, 20)
+		c.Write(3, 30) // 2 is replaced into b1
+		if value, isCacheMiss := c.Read(2); !isCacheMiss || value != 0 {
+			t.Fatalf("expected a miss for a key in b1 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+	})
+	t.Run("size invariants hold for random workloads", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(3))
+		for _, size := range []int{1, 2, 5, 16} {
+			c := newARC[int, int](size)
+			for step := 0; step < 10000; step++ {
+				key := rnd.Intn(4 * size)
+				switch op := rnd.Intn(100); {
+				case op < 50:
+					_, _ = c.Read(key)
+				case op < 95:
+					c.Write(key, key)
+				case op < 99:
+					_ = c.Delete(key)
+				default:
+					c.Resize(1 + rnd.Intn(2*size))
+				}
+				t1, t2, b1, b2 := c.t1.Len(), c.t2.Len(), c.b1.Len(), c.b2.Len()
+				if t1+t2 > c.c || t1+b1 > c.c || t1+t2+b1+b2 > 2*c.c || c.p < 0 || c.p > c.c {
+					t.Fatalf("size %d, step %d: invariants broken t1=%d t2=%d b1=%d b2=%d p=%d",
+						c.c, step, t1, t2, b1, b2, c.p)
+				}
+			}
+		}
+	})
+	t.Run("delete forgets ghost keys", func(t *testing.T) {
+		c := newARC[int, int](2)
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		c.Write(2, 20)
+		c.Write(3, 30) // 2 is replaced into b1
+		if c.Delete(2) {
+			t.Fatal("expected delete to report that key 2 is not in the cache")
+		}
+		if c.b1.Len() != 0 {
+			t.Fatalf("expected key 2 to be dropped from b1: %#v", c.state())
+		}
+		c.Write(2, 20)
+		if state := c.state(); state.p != 0 || len(state.t1) != 1 || state.t1[0] != 2 {
+			t.Fatalf("expected writing key 2 again to be a complete miss: %#v", state)
+		}
+	})
+	t.Run("resize scales p and the ghost lists", func(t *testing.T) {
+		c := newARC[int, int](8)
+		for key := 1; key <= 8; key++ {
+			c.Write(key, key)
+		}
+		for key := 5; key <= 8; key++ {
+			_, _ = c.Read(key)
+		}
+		for key := 9; key <= 12; key++ {
+			c.Write(key, key) // replaces 1 to 4 from t1 into b1
+		}
+		c.Write(1, 1) // ghost hit in b1, p grows to 1 and 9 is replaced into b1
+		if state := c.state(); state.p != 1 || len(state.b1) != 4 || len(state.t1) != 3 || len(state.t2) != 5 {
+			t.Fatalf("unexpected state before resizing: %#v", state)
+		}
+		c.Resize(4)
+		expected := arcState[int]{t1: []int{}, t2: []int{1, 8, 7, 6}, b1: []int{12, 11}, b2: []int{}, p: 0}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("unexpected state after shrinking: %#v", state)
+		}
+		c.Resize(16)
+		if state := c.state(); state.p != 0 || c.Capacity() != 16 || c.Len() != 4 {
+			t.Fatalf("unexpected state after growing: %#v", state)
 		}
 	})
 }
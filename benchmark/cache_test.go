@@ -1,22 +1,16 @@
 package benchmark
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/topliceanu/cache"
 )
 
 func BenchmarkCache(b *testing.B) {
-	for _, cacheType := range []string{
-		cache.LRU,
-		cache.LFU,
-		cache.MRU,
-		cache.SLRU,
-		cache.LFRU,
-		cache.ARC,
-	} {
+	for _, cacheType := range cache.Algorithms() {
 		b.Run(cacheType, func(b *testing.B) {
-			c := cache.Factory(cacheType, 1000)
+			c := cache.Factory[int, int](cacheType, 1000)
 
 			b.Run("Write()", func(b *testing.B) {
 				for i := 0; i < b.N; i ++ {
@@ -33,6 +27,43 @@ func BenchmarkCache(b *testing.B) {
 	}
 }
 
+// BenchmarkAdaptive compares ARC with CAR, its clock based counterpart, on
+// a skewed workload. Besides the throughput, it reports the hit rate.
+func BenchmarkAdaptive(b *testing.B) {
+	benchmarkSkewed(b, []string{cache.ARC, cache.CAR})
+}
+
+// BenchmarkFIFO compares the FIFO based strategies, which don't move keys on
+// a hit, with the list based ones on the same workload as BenchmarkAdaptive.
+func BenchmarkFIFO(b *testing.B) {
+	benchmarkSkewed(b, []string{cache.LRU, cache.SLRU, cache.ARC, cache.S3FIFO, cache.SIEVE})
+}
+
+// benchmarkSkewed reads keys drawn from a Zipf distribution, writing the
+// missing ones, and reports the hit rate of each cache type.
+func benchmarkSkewed(b *testing.B, cacheTypes []string) {
+	var (
+		zipf = rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, 100000)
+		keys = make([]int, 1<<16)
+	)
+	for i := range keys {
+		keys[i] = int(zipf.Uint64())
+	}
+	for _, cacheType := range cacheTypes {
+		b.Run(cacheType, func(b *testing.B) {
+			c := cache.Factory[int, int](cacheType, 1000)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				if _, isCacheMiss := c.Read(key); isCacheMiss {
+					c.Write(key, key)
+				}
+			}
+			b.ReportMetric(c.Stats().HitRate()*100, "hit%")
+		})
+	}
+}
+
 /*
 import (
 	"testing"
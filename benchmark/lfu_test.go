@@ -0,0 +1,33 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/topliceanu/cache"
+)
+
+// BenchmarkLFU compares the heap based LFU with the constant time one on a
+// skewed workload, where a few keys are requested much more often than others.
+func BenchmarkLFU(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		keys := make([]int, 1<<16)
+		zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, uint64(size*10))
+		for i := range keys {
+			keys[i] = int(zipf.Uint64())
+		}
+		for _, cacheType := range []string{cache.LFU, cache.LFUList} {
+			b.Run(fmt.Sprintf("%s/size=%d", cacheType, size), func(b *testing.B) {
+				c := cache.Factory[int, int](cacheType, size)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					key := keys[i%len(keys)]
+					if _, isCacheMiss := c.Read(key); isCacheMiss {
+						c.Write(key, key)
+					}
+				}
+			})
+		}
+	}
+}
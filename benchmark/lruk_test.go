@@ -0,0 +1,34 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/topliceanu/cache"
+)
+
+// BenchmarkLRUK compares LRU-K with LRU on a skewed workload, to show the cost
+// of keeping the resident keys ordered by their K-th most recent request as
+// the cache grows.
+func BenchmarkLRUK(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		keys := make([]int, 1<<16)
+		zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, uint64(size*10))
+		for i := range keys {
+			keys[i] = int(zipf.Uint64())
+		}
+		for _, cacheType := range []string{cache.LRU, cache.LRUK} {
+			b.Run(fmt.Sprintf("%s/size=%d", cacheType, size), func(b *testing.B) {
+				c := cache.Factory[int, int](cacheType, size)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					key := keys[i%len(keys)]
+					if _, isCacheMiss := c.Read(key); isCacheMiss {
+						c.Write(key, key)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package benchmark
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/topliceanu/cache"
+)
+
+// BenchmarkParallel compares a single cache guarded by one mutex with a
+// sharded cache as the number of goroutines hitting it grows.
+func BenchmarkParallel(b *testing.B) {
+	for _, cacheType := range cache.Algorithms() {
+		b.Run(cacheType, func(b *testing.B) {
+			for _, mode := range []struct {
+				name string
+				opt  cache.Option
+			}{
+				{"locked", cache.WithConcurrency()},
+				{"sharded", cache.WithShards(32)},
+			} {
+				for _, goroutines := range []int{1, 2, 4, 8, 16, 32} {
+					b.Run(fmt.Sprintf("%s/goroutines=%d", mode.name, goroutines), func(b *testing.B) {
+						c := cache.Factory[int, int](cacheType, 1000, mode.opt)
+						runParallel(b, goroutines, func(i int) {
+							key := i % 2000
+							if _, isCacheMiss := c.Read(key); isCacheMiss {
+								c.Write(key, key)
+							}
+						})
+					})
+				}
+			}
+		})
+	}
+}
+
+// runParallel splits b.N iterations of op between exactly n goroutines.
+func runParallel(b *testing.B, n int, op func(i int)) {
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < b.N; i += n {
+				op(i * 7919)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// newCache assumes the settings were validated, see New.
+func newCache[K comparable, V any](p Policy[K, *entry[K, V]], s *settings) *cache[K, V] {
+	c := &cache[K, V]{
+		policy:      p,
+		clock:       s.clock,
+		ttl:         s.ttl,
+		expiry:      expiryHeap[K, V]{},
+		recordStats: !s.noStats,
+		minSize:     1,
+	}
+	c.onEvict, _ = evictHandler[K, V](s)
+	p.SetEvictHandler(func(key K, e *entry[K, V]) {
+		c.evict(e, EvictionCapacity)
+	})
+	return c
+}
+
+// cache implements Cache
+// It wraps any replacement policy with the features that don't depend on the
+// strategy, like entry expiration, eviction callbacks and stats. The policy stores
+// entries which carry the user's value along with its expiration time.
+type cache[K comparable, V any] struct {
+	policy  Policy[K, *entry[K, V]]
+	clock   Clock
+	ttl     time.Duration
+	expiry  expiryHeap[K, V]
+	onEvict func(key K, value V, reason EvictionReason)
+	stats   Stats
+	// recordStats is false when the counters are disabled with WithoutStats.
+	recordStats bool
+	// algorithm and minSize are used to validate Resize.
+	algorithm string
+	minSize   int
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time // zero value means the entry never expires
+	index     int       // index of the entry in the expiry heap, -1 if not there
+}
+
+func (c *cache[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	e, isCacheMiss := c.policy.Read(key)
+	if isCacheMiss {
+		c.count(&c.stats.Misses)
+		return value, true
+	}
+	if e.isExpired(c.clock.Now()) {
+		c.count(&c.stats.Misses)
+		c.reclaim(e)
+		return value, true
+	}
+	c.count(&c.stats.Hits)
+	return e.value, false
+}
+
+func (c *cache[K, V]) Write(key K, value V) {
+	c.WriteWithTTL(key, value, c.ttl)
+}
+
+func (c *cache[K, V]) WriteWithTTL(key K, value V, ttl time.Duration) {
+	c.policy.Write(key, c.store(key, value, ttl))
+}
+
+func (c *cache[K, V]) WriteWithCost(key K, value V, cost float64, size int) {
+	e := c.store(key, value, c.ttl)
+	if p, ok := c.policy.(costAware[K, *entry[K, V]]); ok {
+		p.WriteWithCost(key, e, cost, size)
+		return
+	}
+	c.policy.Write(key, e)
+}
+
+// store prepares the entry which the policy is about to store for the key,
+// reclaiming the expired entries and counting the write.
+func (c *cache[K, V]) store(key K, value V, ttl time.Duration) *entry[K, V] {
+	now := c.clock.Now()
+	// Make room by dropping expired entries before the policy gets to pick a victim.
+	c.expire(now)
+
+	e, found := c.policy.Peek(key)
+	if found {
+		c.count(&c.stats.Updates)
+		c.notify(e, EvictionReplaced)
+	} else {
+		c.count(&c.stats.Writes)
+		e = &entry[K, V]{key: key, index: -1}
+	}
+	e.value = value
+	e.expiresAt = time.Time{}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+	c.track(e)
+	return e
+}
+
+// Delete reports expired entries as not found, but still reclaims them.
+func (c *cache[K, V]) Delete(key K) bool {
+	e, found := c.policy.Peek(key)
+	if !found {
+		// The policy might still remember the key, eg. in arc's ghost lists.
+		_ = c.policy.Delete(key)
+		return false
+	}
+	if e.isExpired(c.clock.Now()) {
+		c.reclaim(e)
+		return false
+	}
+	if e.index >= 0 {
+		heap.Remove(&c.expiry, e.index)
+	}
+	_ = c.policy.Delete(key)
+	c.notify(e, EvictionDeleted)
+	return true
+}
+
+func (c *cache[K, V]) Peek(key K) (value V, found bool) {
+	e, found := c.policy.Peek(key)
+	if !found || e.isExpired(c.clock.Now()) {
+		return value, false
+	}
+	return e.value, true
+}
+
+func (c *cache[K, V]) Contains(key K) bool {
+	_, found := c.Peek(key)
+	return found
+}
+
+// Len reclaims expired entries so they are not counted.
+func (c *cache[K, V]) Len() int {
+	c.expire(c.clock.Now())
+	return c.policy.Len()
+}
+
+func (c *cache[K, V]) Capacity() int {
+	return c.policy.Capacity()
+}
+
+func (c *cache[K, V]) Purge() {
+	if c.onEvict != nil {
+		for _, key := range c.policy.Keys() {
+			e, _ := c.policy.Peek(key)
+			c.notify(e, EvictionDeleted)
+		}
+	}
+	for _, e := range c.expiry {
+		e.index = -1
+	}
+	c.expiry = expiryHeap[K, V]{}
+	c.policy.Purge()
+}
+
+// Resize reclaims expired entries first, so that they go before any other
+// entry the policy would evict to fit in the new size.
+func (c *cache[K, V]) Resize(size int) error {
+	if size < c.minSize {
+		return &InvalidSizeError{Algorithm: c.algorithm, Size: size, Min: c.minSize}
+	}
+	c.expire(c.clock.Now())
+	c.policy.Resize(size)
+	return nil
+}
+
+// Stats reclaims expired entries first, like Len, so that they are not counted.
+func (c *cache[K, V]) Stats() Stats {
+	c.expire(c.clock.Now())
+	stats := c.stats
+	stats.Len = c.policy.Len()
+	if s, ok := c.policy.(segmented); ok {
+		stats.Segments = s.Segments()
+	}
+	return stats
+}
+
+func (c *cache[K, V]) ResetStats() {
+	c.stats = Stats{}
+}
+
+// Helpers
+
+func (e *entry[K, V]) isExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// track adds, moves or removes the entry in the expiry heap according to its expiration time.
+func (c *cache[K, V]) track(e *entry[K, V]) {
+	switch {
+	case e.index >= 0 && e.expiresAt.IsZero():
+		heap.Remove(&c.expiry, e.index)
+	case e.index >= 0:
+		heap.Fix(&c.expiry, e.index)
+	case !e.expiresAt.IsZero():
+		heap.Push(&c.expiry, e)
+	}
+}
+
+// expire reclaims all the entries which expired by now.
+func (c *cache[K, V]) expire(now time.Time) {
+	for len(c.expiry) > 0 && c.expiry[0].isExpired(now) {
+		c.reclaim(c.expiry[0])
+	}
+}
+
+// reclaim removes an expired entry from the policy. The policy might have
+// already evicted it, eg. mru evicts the keys it reads.
+func (c *cache[K, V]) reclaim(e *entry[K, V]) {
+	if e.index >= 0 {
+		heap.Remove(&c.expiry, e.index)
+	}
+	if c.policy.Delete(e.key) {
+		c.count(&c.stats.Evictions)
+		c.notify(e, EvictionExpired)
+	}
+}
+
+// evict stops tracking an entry which the policy evicted and reports it.
+func (c *cache[K, V]) evict(e *entry[K, V], reason EvictionReason) {
+	if e.index >= 0 {
+		heap.Remove(&c.expiry, e.index)
+	}
+	c.count(&c.stats.Evictions)
+	c.notify(e, reason)
+}
+
+// count increments one of the counters, unless stats are disabled.
+func (c *cache[K, V]) count(counter *uint64) {
+	if c.recordStats {
+		*counter++
+	}
+}
+
+func (c *cache[K, V]) notify(e *entry[K, V], reason EvictionReason) {
+	if c.onEvict != nil {
+		c.onEvict(e.key, e.value, reason)
+	}
+}
+
+// expiryHeap implements heap.Interface and keeps the entry which expires
+// first at the head of the slice.
+type expiryHeap[K comparable, V any] []*entry[K, V]
+
+func (h expiryHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h expiryHeap[K, V]) Less(i, j int) bool {
+	return h[i].expiresAt.Before(h[j].expiresAt)
+}
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap[K, V]) Push(x interface{}) {
+	e := x.(*entry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K, V]) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.index = -1
+	*h = old[:len(old)-1]
+	return e
+}
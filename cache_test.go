@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock implements Clock and only moves forward when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestCache(t *testing.T) {
+	t.Run("expired entries are reported as cache misses", func(t *testing.T) {
+		clock := newFakeClock()
+		c := Factory[int, int](LRU, 2, WithClock(clock))
+		c.WriteWithTTL(1, 10, time.Second)
+		c.Write(2, 20)
+		if value, isCacheMiss := c.Read(1); isCacheMiss || value != 10 {
+			t.Fatalf("expected a hit before expiration but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+		clock.advance(time.Second)
+		if value, isCacheMiss := c.Read(1); !isCacheMiss || value != 0 {
+			t.Fatalf("expected a miss after expiration but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+		if value, isCacheMiss := c.Read(2); isCacheMiss || value != 20 {
+			t.Fatalf("expected entries without a ttl to never expire but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+	})
+	t.Run("default ttl applies to Write but can be overridden", func(t *testing.T) {
+		clock := newFakeClock()
+		c := Factory[int, int](LRU, 4, WithClock(clock), WithTTL(time.Minute))
+		c.Write(1, 10)
+		c.WriteWithTTL(2, 20, time.Hour)
+		c.WriteWithTTL(3, 30, 0)
+		clock.advance(2 * time.Minute)
+		if _, isCacheMiss := c.Read(1); !isCacheMiss {
+			t.Fatal("expected key 1 to expire with the default ttl")
+		}
+		if _, isCacheMiss := c.Read(2); isCacheMiss {
+			t.Fatal("expected key 2 to be kept for its own ttl")
+		}
+		if _, isCacheMiss := c.Read(3); isCacheMiss {
+			t.Fatal("expected key 3 to never expire")
+		}
+	})
+	t.Run("overwriting a key resets its ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		c := newCache(newLRU[int, *entry[int, int]](2), &settings{clock: clock})
+		c.WriteWithTTL(1, 10, time.Second)
+		c.WriteWithTTL(1, 11, time.Minute)
+		if len(c.expiry) != 1 {
+			t.Fatalf("expected one entry in the expiry heap but got %d", len(c.expiry))
+		}
+		clock.advance(time.Second)
+		if value, isCacheMiss := c.Read(1); isCacheMiss || value != 11 {
+			t.Fatalf("expected the new ttl to apply but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+		c.Write(1, 12)
+		if len(c.expiry) != 0 {
+			t.Fatalf("expected the entry to leave the expiry heap once it no longer has a ttl but got %d", len(c.expiry))
+		}
+	})
+	for _, tc := range []struct {
+		algorithm string
+		size      int
+		expiring  int
+		surviving int
+	}{
+		{LRU, 2, 2, 1},
+		{LFU, 2, 2, 1},
+		{LFUList, 2, 2, 1},
+		{MRU, 2, 1, 2},
+		{SLRU, 4, 2, 1},
+		{LFRU, 4, 2, 1},
+		{ARC, 8, 2, 1},
+	} {
+		t.Run(tc.algorithm+" reclaims expired entries ahead of the policy's victims", func(t *testing.T) {
+			clock := newFakeClock()
+			c := Factory[int, int](tc.algorithm, tc.size, WithClock(clock))
+			for _, key := range []int{1, 2} {
+				if key == tc.expiring {
+					c.WriteWithTTL(key, key*10, time.Second)
+				} else {
+					c.Write(key, key*10)
+				}
+			}
+			clock.advance(time.Minute)
+			c.Write(3, 30)
+			for _, key := range []int{tc.surviving, 3} {
+				if value, isCacheMiss := c.Read(key); isCacheMiss || value != key*10 {
+					t.Fatalf("expected key %d to be kept but got value=%d, isCacheMiss=%t", key, value, isCacheMiss)
+				}
+			}
+		})
+	}
+	t.Run("expiry heap drops entries evicted by the policy", func(t *testing.T) {
+		c := newCache(newLRU[int, *entry[int, int]](2), &settings{clock: newFakeClock()})
+		for i := 0; i < 10; i++ {
+			c.WriteWithTTL(i, i, time.Hour)
+		}
+		if len(c.expiry) != 2 {
+			t.Fatalf("expected the expiry heap to only hold the 2 entries in the cache but it holds %d", len(c.expiry))
+		}
+	})
+	t.Run("eviction handler is called with the reason of each eviction", func(t *testing.T) {
+		type eviction struct {
+			key, value int
+			reason     EvictionReason
+		}
+		var (
+			clock     = newFakeClock()
+			evictions = []eviction{}
+			c         = Factory[int, int](LRU, 2, WithClock(clock), OnEvict(func(key, value int, reason EvictionReason) {
+				evictions = append(evictions, eviction{key, value, reason})
+			}))
+		)
+		c.Write(1, 10)
+		c.Write(1, 11)
+		c.WriteWithTTL(2, 20, time.Second)
+		c.Write(3, 30)
+		clock.advance(time.Minute)
+		c.WriteWithTTL(4, 40, time.Second)
+		clock.advance(time.Minute)
+		_, _ = c.Read(4)
+		expected := []eviction{
+			{1, 10, EvictionReplaced},
+			{1, 11, EvictionCapacity},
+			{2, 20, EvictionExpired},
+			{4, 40, EvictionExpired},
+		}
+		if !reflect.DeepEqual(evictions, expected) {
+			t.Fatalf("unexpected evictions: %v", evictions)
+		}
+	})
+	t.Run("delete and purge are reported to the eviction handler", func(t *testing.T) {
+		evicted := map[int]EvictionReason{}
+		c := Factory[int, int](SLRU, 4, OnEvict(func(key, value int, reason EvictionReason) {
+			evicted[key] = reason
+		}))
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		c.Delete(3)
+		c.Purge()
+		expected := map[int]EvictionReason{1: EvictionDeleted, 2: EvictionDeleted, 3: EvictionDeleted}
+		if !reflect.DeepEqual(evicted, expected) {
+			t.Fatalf("unexpected evictions: %v", evicted)
+		}
+	})
+	t.Run("expired entries are neither found nor counted", func(t *testing.T) {
+		clock := newFakeClock()
+		c := Factory[int, int](LRU, 2, WithClock(clock))
+		c.WriteWithTTL(1, 10, time.Second)
+		clock.advance(time.Minute)
+		if c.Contains(1) || c.Len() != 0 || c.Delete(1) {
+			t.Fatalf("expected expired key 1 to be gone, len=%d", c.Len())
+		}
+	})
+	t.Run("eviction handler must match the cache types", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected a panic for a handler of the wrong type")
+			}
+		}()
+		_ = Factory[string, int](LRU, 2, OnEvict(func(key, value int, reason EvictionReason) {}))
+	})
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" reports every entry leaving the cache exactly once", func(t *testing.T) {
+			var (
+				rnd      = rand.New(rand.NewSource(11))
+				clock    = newFakeClock()
+				resident = map[int]bool{}
+				c        = Factory[int, int](algorithm, 8, WithClock(clock), OnEvict(func(key, value int, reason EvictionReason) {
+					if !resident[key] {
+						t.Fatalf("key %d was evicted (%s) but it's not in the cache", key, reason)
+					}
+					if reason != EvictionReplaced {
+						delete(resident, key)
+					}
+				}))
+				p = c.(*cache[int, int]).policy
+			)
+			for step := 0; step < 5000; step++ {
+				key := rnd.Intn(24)
+				switch op := rnd.Intn(10); {
+				case op < 5:
+					_, _ = c.Read(key)
+				case op < 8:
+					c.Write(key, key)
+					resident[key] = true
+				case op < 9:
+					c.WriteWithTTL(key, key, time.Duration(rnd.Intn(10))*time.Second)
+					resident[key] = true
+				default:
+					clock.advance(time.Second)
+				}
+				if len(resident) != p.Len() {
+					t.Fatalf("step %d: %d keys should be in the cache but the policy holds %d", step, len(resident), p.Len())
+				}
+				for key := range resident {
+					if _, found := p.Peek(key); !found {
+						t.Fatalf("step %d: key %d was never reported as evicted", step, key)
+					}
+				}
+			}
+		})
+	}
+}
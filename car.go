@@ -0,0 +1,187 @@
+package cache
+
+// car implements Policy
+// It follows the CAR algorithm as described in "CAR: Clock with Adaptive
+// Replacement" by Sorav Bansal and Dharmendra S. Modha.
+// CAR is ARC with clocks instead of LRU lists for t1 and t2: a hit only sets
+// the reference bit of the key, and the hands skip referenced keys when
+// looking for one to replace.
+type car[K comparable, V any] struct {
+	evictNotifier[K, V]
+	adaptive[K]
+	t1, t2 clockRing[K, V]
+}
+
+func newCAR[K comparable, V any](size int) *car[K, V] {
+	return &car[K, V]{
+		adaptive: newAdaptive[K](size),
+		t1:       newClockRing[K, V](),
+		t2:       newClockRing[K, V](),
+	}
+}
+
+func (c *car[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node := c.find(key)
+	if node == nil {
+		return value, true
+	}
+	node.count = 1
+	return node.value, false
+}
+
+func (c *car[K, V]) Write(key K, value V) {
+	if node := c.find(key); node != nil {
+		node.value = value
+		node.count = 1
+		return
+	}
+	_, inB1 := c.b1.hash[key]
+	_, inB2 := c.b2.hash[key]
+	if c.Len() >= c.c {
+		c.replace()
+		// The directory only remembers c keys requested once and 2c keys overall.
+		if !inB1 && !inB2 {
+			if c.t1.len()+c.b1.Len() >= c.c && c.b1.Len() > 0 {
+				_ = c.b1.remove(c.b1.last.key)
+			} else if c.Len()+c.b1.Len()+c.b2.Len() >= 2*c.c && c.b2.Len() > 0 {
+				_ = c.b2.remove(c.b2.last.key)
+			}
+		}
+	}
+	node := &clockNode[K, V]{key: key, value: value}
+	switch {
+	case inB1:
+		// Ghost hit in b1, favour recency by increasing t1's target size.
+		c.p = min(c.c, c.p+max(c.b2.Len()/c.b1.Len(), 1))
+		_ = c.b1.remove(key)
+		c.t2.insert(node)
+	case inB2:
+		// Ghost hit in b2, favour frequency by decreasing t1's target size.
+		c.p = max(0, c.p-max(c.b1.Len()/c.b2.Len(), 1))
+		_ = c.b2.remove(key)
+		c.t2.insert(node)
+	default:
+		c.t1.insert(node)
+	}
+}
+
+// Peek leaves the reference bit alone, so the key doesn't get another turn from the hands.
+func (c *car[K, V]) Peek(key K) (value V, found bool) {
+	if node := c.find(key); node != nil {
+		return node.value, true
+	}
+	return value, false
+}
+
+// Delete unlinks the key from its clock, moving the hand past it if needed.
+func (c *car[K, V]) Delete(key K) bool {
+	if c.forget(key) {
+		return false
+	}
+	if node, found := c.t1.hash[key]; found {
+		c.t1.remove(node)
+		return true
+	}
+	if node, found := c.t2.hash[key]; found {
+		c.t2.remove(node)
+		return true
+	}
+	return false
+}
+
+func (c *car[K, V]) Len() int {
+	return c.t1.len() + c.t2.len()
+}
+
+func (c *car[K, V]) Capacity() int {
+	return c.c
+}
+
+// Keys returns the keys of t1 then t2, each in the order their hand will reach them.
+func (c *car[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	collect := func(node *clockNode[K, V]) {
+		keys = append(keys, node.key)
+	}
+	c.t1.each(collect)
+	c.t2.each(collect)
+	return keys
+}
+
+func (c *car[K, V]) Purge() {
+	c.t1 = newClockRing[K, V]()
+	c.t2 = newClockRing[K, V]()
+	c.reset()
+}
+
+// Resize scales p and the ghost lists to the new size. When shrinking, the
+// hands sweep the clocks as they do on a miss, clearing reference bits and
+// moving the referenced keys of t1 to t2, until the keys fit.
+func (c *car[K, V]) Resize(size int) {
+	b1Size, b2Size := c.rescale(size)
+	for c.Len() > c.c {
+		c.replace()
+	}
+	c.trim(b1Size, b2Size, c.t1.len(), c.t2.len())
+}
+
+// replace makes room for a new key by moving the first key without a
+// reference bit of either t1 or t2 into its ghost list, based on the target
+// size p. The value is evicted, only the key is remembered. On their way,
+// the hands clear the reference bits, moving the keys of t1 to t2.
+func (c *car[K, V]) replace() {
+	for {
+		if c.t1.len() >= max(1, c.p) {
+			node := c.t1.hand
+			c.t1.remove(node)
+			if node.count == 0 {
+				c.b1.insert(node.key, struct{}{})
+				c.notifyEvict(node.key, node.value)
+				return
+			}
+			node.count = 0
+			c.t2.insert(node)
+			continue
+		}
+		node := c.t2.hand
+		if node.count == 0 {
+			c.t2.remove(node)
+			c.b2.insert(node.key, struct{}{})
+			c.notifyEvict(node.key, node.value)
+			return
+		}
+		node.count = 0
+		c.t2.hand = node.next
+	}
+}
+
+// find returns the node of a key in t1 or t2, or nil.
+func (c *car[K, V]) find(key K) *clockNode[K, V] {
+	if node, found := c.t1.hash[key]; found {
+		return node
+	}
+	return c.t2.hash[key]
+}
+
+type carState[K comparable] struct {
+	t1, t2 []K // keys from the hand of each clock
+	b1, b2 []K // keys from most to least recently used
+	p      int
+}
+
+func (c *car[K, V]) state() carState[K] {
+	s := carState[K]{
+		t1: []K{},
+		t2: []K{},
+		b1: c.b1.Keys(),
+		b2: c.b2.Keys(),
+		p:  c.p,
+	}
+	c.t1.each(func(node *clockNode[K, V]) {
+		s.t1 = append(s.t1, node.key)
+	})
+	c.t2.each(func(node *clockNode[K, V]) {
+		s.t2 = append(s.t2, node.key)
+	})
+	return s
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCAR(t *testing.T) {
+	t.Run("replays the cases of the algorithm", func(t *testing.T) {
+		c := newCAR[int, int](4)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for idx, step := range []struct {
+			op       string
+			key      int
+			expected carState[int]
+			evicted  []int // keys evicted by the step
+		}{
+			{"write", 1, carState[int]{t1: []int{1}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			{"write", 2, carState[int]{t1: []int{1, 2}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			{"write", 3, carState[int]{t1: []int{1, 2, 3}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			{"write", 4, carState[int]{t1: []int{1, 2, 3, 4}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			// t1 and b1 hold c keys, so the key replaced into b1 is dropped.
+			{"write", 5, carState[int]{t1: []int{2, 3, 4, 5}, t2: []int{}, b1: []int{}, b2: []int{}}, []int{1}},
+			// Hits only set the reference bit, keys don't move.
+			{"read", 3, carState[int]{t1: []int{2, 3, 4, 5}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			{"read", 4, carState[int]{t1: []int{2, 3, 4, 5}, t2: []int{}, b1: []int{}, b2: []int{}}, nil},
+			{"write", 6, carState[int]{t1: []int{3, 4, 5, 6}, t2: []int{}, b1: []int{}, b2: []int{}}, []int{2}},
+			// t1's hand moves the referenced keys to t2 and replaces the first one without a reference bit.
+			{"write", 7, carState[int]{t1: []int{6, 7}, t2: []int{3, 4}, b1: []int{5}, b2: []int{}}, []int{5}},
+			{"write", 8, carState[int]{t1: []int{7, 8}, t2: []int{3, 4}, b1: []int{6, 5}, b2: []int{}}, []int{6}},
+			// Ghost hit in b1 grows p.
+			{"write", 5, carState[int]{t1: []int{8}, t2: []int{3, 4, 5}, b1: []int{7, 6}, b2: []int{}, p: 1}, []int{7}},
+			{"write", 9, carState[int]{t1: []int{9}, t2: []int{3, 4, 5}, b1: []int{8, 7, 6}, b2: []int{}, p: 1}, []int{8}},
+			{"read", 9, carState[int]{t1: []int{9}, t2: []int{3, 4, 5}, b1: []int{8, 7, 6}, b2: []int{}, p: 1}, nil},
+			// 9 moves to t2, then t1 is below its target so t2's hand replaces 3 into b2.
+			{"write", 10, carState[int]{t1: []int{10}, t2: []int{4, 5, 9}, b1: []int{8, 7, 6}, b2: []int{3}, p: 1}, []int{3}},
+			// Ghost hit in b2 shrinks p.
+			{"write", 3, carState[int]{t1: []int{}, t2: []int{4, 5, 9, 3}, b1: []int{10, 8, 7, 6}, b2: []int{}, p: 0}, []int{10}},
+		} {
+			evicted = evicted[:0]
+			if step.op == "write" {
+				c.Write(step.key, step.key*10)
+			} else if _, isCacheMiss := c.Read(step.key); isCacheMiss {
+				t.Fatalf("step %d: expected a hit for key %d", idx, step.key)
+			}
+			if state := c.state(); !reflect.DeepEqual(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", idx, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", idx, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("deleted keys are forgotten by the ghost lists", func(t *testing.T) {
+		c := newCAR[int, int](2)
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		c.Write(2, 20)
+		c.Write(3, 30) // 1 moves to t2, 2 is replaced into b1
+		if c.Delete(2) || !c.Delete(1) || c.Delete(1) {
+			t.Fatalf("unexpected deletes, state %#v", c.state())
+		}
+		c.Write(2, 20)
+		expected := carState[int]{t1: []int{3, 2}, t2: []int{}, b1: []int{}, b2: []int{}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("expected key 2 to be written in t1 again but got %#v", state)
+		}
+	})
+	t.Run("the directory never holds more than twice the capacity", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(9))
+		c := newCAR[int, int](10)
+		for i := 0; i < 10000; i++ {
+			key := rnd.Intn(40)
+			switch op := rnd.Intn(20); {
+			case op == 0:
+				c.Resize(1 + rnd.Intn(15))
+			case op < 10:
+				_, _ = c.Read(key)
+			default:
+				c.Write(key, key)
+			}
+			if c.Len() > c.c || c.t1.len()+c.b1.Len() > c.c || c.Len()+c.b1.Len()+c.b2.Len() > 2*c.c || c.p > c.c {
+				t.Fatalf("step %d: invariants don't hold for capacity %d: %#v", i, c.c, c.state())
+			}
+		}
+	})
+	t.Run("hit rate is close to arc's", func(t *testing.T) {
+		trace := skewedTrace(2, 10000, 100000, false)
+		if car, arc := hitRate(CAR, 200, trace), hitRate(ARC, 200, trace); car < arc-0.02 {
+			t.Fatalf("expected car to be within 2%% of arc but got %f and %f", car, arc)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"time"
+)
+
+// Clock tells the current time to caches holding entries with a TTL.
+// It can be replaced with WithClock, eg. to control time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock implements Clock using the wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
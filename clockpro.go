@@ -0,0 +1,310 @@
+package cache
+
+// clockProPage is the status of a key in CLOCK-Pro.
+type clockProPage int
+
+const (
+	clockProCold        clockProPage = iota // resident, requested rarely
+	clockProHot                             // resident, requested again during its test period
+	clockProNonResident                     // evicted during its test period, only the key is remembered
+)
+
+func newClockPro[K comparable, V any](size int) *clockPro[K, V] {
+	return &clockPro[K, V]{
+		c:          size,
+		coldTarget: size,
+		hash:       make(map[K]*clockNode[K, V]),
+	}
+}
+
+// clockPro implements Policy
+// It follows CLOCK-Pro as described in "CLOCK-Pro: An Effective Improvement
+// of the CLOCK Replacement" by Song Jiang, Feng Chen and Xiaodong Zhang.
+// CLOCK-Pro approximates LIRS with a single clock swept by three hands: hot
+// keys match LIR keys and cold keys resident HIR keys.
+type clockPro[K comparable, V any] struct {
+	evictNotifier[K, V]
+	c              int
+	coldTarget     int // number of cold keys to keep, between 1 and c
+	hash           map[K]*clockNode[K, V]
+	handHot        *clockNode[K, V] // new keys are inserted just behind it
+	handCold       *clockNode[K, V]
+	handTest       *clockNode[K, V]
+	numHot         int
+	numCold        int
+	numNonResident int
+}
+
+func (c *clockPro[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node, exists := c.hash[key]
+	if !exists || node.page == clockProNonResident {
+		return value, true
+	}
+	node.count = 1
+	return node.value, false
+}
+
+func (c *clockPro[K, V]) Write(key K, value V) {
+	node, exists := c.hash[key]
+	if exists && node.page != clockProNonResident {
+		node.value = value
+		node.count = 1
+		return
+	}
+	if exists {
+		// The key was requested again during its test period, there should be more cold keys.
+		c.coldTarget = min(c.c, c.coldTarget+1)
+		c.unlink(node)
+		c.numNonResident--
+		node.page = clockProHot
+		node.test = false
+		node.count = 0
+	} else {
+		node = &clockNode[K, V]{key: key, page: clockProCold, test: true}
+		c.hash[key] = node
+	}
+	node.value = value
+	for c.Len() >= c.c {
+		c.evict()
+	}
+	c.insert(node)
+	if node.page == clockProHot {
+		c.numHot++
+		c.balance()
+	} else {
+		c.numCold++
+	}
+	for c.numNonResident > c.c {
+		c.runHandTest()
+	}
+}
+
+func (c *clockPro[K, V]) Peek(key K) (value V, found bool) {
+	if node, exists := c.hash[key]; exists && node.page != clockProNonResident {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (c *clockPro[K, V]) Delete(key K) bool {
+	node, exists := c.hash[key]
+	if !exists {
+		return false
+	}
+	c.remove(node)
+	return node.page != clockProNonResident
+}
+
+func (c *clockPro[K, V]) Len() int {
+	return c.numHot + c.numCold
+}
+
+func (c *clockPro[K, V]) Capacity() int {
+	return c.c
+}
+
+// Keys returns the resident keys, starting with handHot.
+func (c *clockPro[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	c.each(func(node *clockNode[K, V]) {
+		if node.page != clockProNonResident {
+			keys = append(keys, node.key)
+		}
+	})
+	return keys
+}
+
+func (c *clockPro[K, V]) Purge() {
+	c.hash = make(map[K]*clockNode[K, V])
+	c.handHot, c.handCold, c.handTest = nil, nil, nil
+	c.numHot, c.numCold, c.numNonResident = 0, 0, 0
+	c.coldTarget = c.c
+}
+
+// Resize scales the cold target to the new size. When shrinking, cold keys
+// are evicted and hot keys turned into cold keys as usual until they fit.
+func (c *clockPro[K, V]) Resize(size int) {
+	c.c = size
+	c.coldTarget = max(1, min(c.c, c.coldTarget))
+	c.balance()
+	for c.Len() > c.c {
+		c.evict()
+	}
+	for c.numNonResident > c.c {
+		c.runHandTest()
+	}
+}
+
+// evict moves handCold until it finds a cold key which was not requested
+// since its last turn. Requested cold keys move to the head of the list,
+// those in their test period become hot.
+func (c *clockPro[K, V]) evict() {
+	for {
+		node := c.handCold
+		c.handCold = node.next
+		if node.page != clockProCold {
+			continue
+		}
+		if node.count == 0 {
+			c.notifyEvict(node.key, node.value)
+			if !node.test {
+				c.remove(node)
+				return
+			}
+			c.numCold--
+			node.page = clockProNonResident
+			var zero V
+			node.value = zero
+			c.numNonResident++
+			return
+		}
+		node.count = 0
+		c.unlink(node)
+		c.insert(node)
+		if !node.test {
+			node.test = true
+			continue
+		}
+		node.page = clockProHot
+		node.test = false
+		c.numCold--
+		c.numHot++
+		c.balance()
+	}
+}
+
+// balance turns hot keys into cold keys until they fit in the capacity left by the cold target.
+func (c *clockPro[K, V]) balance() {
+	for c.numHot > c.c-c.coldTarget {
+		c.runHandHot()
+	}
+}
+
+// runHandHot moves handHot until it finds a hot key which was not
+// requested since its last turn and turns it into a cold key. On its way,
+// it ends the test periods of cold keys on behalf of handTest.
+func (c *clockPro[K, V]) runHandHot() {
+	for {
+		node := c.handHot
+		c.handHot = node.next
+		switch node.page {
+		case clockProHot:
+			if node.count == 0 {
+				node.page = clockProCold
+				c.numHot--
+				c.numCold++
+				return
+			}
+			node.count = 0
+		default:
+			c.endTest(node)
+		}
+	}
+}
+
+// runHandTest moves handTest until it removes a non-resident key, ending
+// the test periods of the cold keys it passes.
+func (c *clockPro[K, V]) runHandTest() {
+	for {
+		node := c.handTest
+		c.handTest = node.next
+		if node.page == clockProNonResident {
+			c.endTest(node)
+			return
+		}
+		c.endTest(node)
+	}
+}
+
+// endTest ends the test period of a cold key, which means it was not
+// requested again soon enough to become hot, so there should be fewer
+// cold keys. Non-resident keys are forgotten.
+func (c *clockPro[K, V]) endTest(node *clockNode[K, V]) {
+	switch {
+	case node.page == clockProNonResident:
+		c.remove(node)
+	case node.page == clockProCold && node.test:
+		node.test = false
+	default:
+		return
+	}
+	c.coldTarget = max(1, c.coldTarget-1)
+}
+
+// insert adds the node at the head of the list, just behind handHot.
+func (c *clockPro[K, V]) insert(node *clockNode[K, V]) {
+	if c.handHot == nil {
+		node.link(nil)
+		c.handHot, c.handCold, c.handTest = node, node, node
+		return
+	}
+	node.link(c.handHot)
+}
+
+// unlink removes the node from the list, moving the hands which point at it forward.
+func (c *clockPro[K, V]) unlink(node *clockNode[K, V]) {
+	next := node.next
+	if next == node {
+		next = nil
+	}
+	for _, hand := range []**clockNode[K, V]{&c.handHot, &c.handCold, &c.handTest} {
+		if *hand == node {
+			*hand = next
+		}
+	}
+	node.unlink()
+}
+
+// remove forgets the key altogether.
+func (c *clockPro[K, V]) remove(node *clockNode[K, V]) {
+	c.unlink(node)
+	delete(c.hash, node.key)
+	switch node.page {
+	case clockProHot:
+		c.numHot--
+	case clockProCold:
+		c.numCold--
+	case clockProNonResident:
+		c.numNonResident--
+	}
+}
+
+// each calls fn for every node, starting with handHot.
+func (c *clockPro[K, V]) each(fn func(node *clockNode[K, V])) {
+	if c.handHot == nil {
+		return
+	}
+	node := c.handHot
+	for {
+		next := node.next
+		fn(node)
+		if node = next; node == c.handHot {
+			return
+		}
+	}
+}
+
+type clockProState[K comparable] struct {
+	keys        []K // from handHot
+	hot         []K
+	test        []K // cold keys in their test period, resident or not
+	nonResident []K
+	coldTarget  int
+}
+
+func (c *clockPro[K, V]) state() clockProState[K] {
+	s := clockProState[K]{coldTarget: c.coldTarget}
+	c.each(func(node *clockNode[K, V]) {
+		s.keys = append(s.keys, node.key)
+		switch {
+		case node.page == clockProHot:
+			s.hot = append(s.hot, node.key)
+		case node.page == clockProNonResident:
+			s.test = append(s.test, node.key)
+			s.nonResident = append(s.nonResident, node.key)
+		case node.test:
+			s.test = append(s.test, node.key)
+		}
+	})
+	return s
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestClockPro(t *testing.T) {
+	t.Run("cold keys requested during their test period become hot", func(t *testing.T) {
+		type step struct {
+			write    bool
+			key      int
+			hit      bool // only checked for reads
+			expected clockProState[int]
+			evicted  []int // keys evicted by the step
+		}
+		steps := []step{ // size=3
+			{write: true, key: 1, expected: clockProState[int]{keys: []int{1}, test: []int{1}, coldTarget: 3}},
+			{write: true, key: 2, expected: clockProState[int]{keys: []int{1, 2}, test: []int{1, 2}, coldTarget: 3}},
+			{write: true, key: 3, expected: clockProState[int]{keys: []int{1, 2, 3}, test: []int{1, 2, 3}, coldTarget: 3}},
+			// 1 is evicted during its test period, it stays in the list.
+			{write: true, key: 4, evicted: []int{1}, expected: clockProState[int]{
+				keys: []int{1, 2, 3, 4}, test: []int{1, 2, 3, 4}, nonResident: []int{1}, coldTarget: 3}},
+			// 1 becomes hot, but the cold target leaves no room for hot keys. handHot
+			// turns it back into a cold key and ends the test periods on its way.
+			{write: true, key: 1, evicted: []int{2}, expected: clockProState[int]{
+				keys: []int{3, 4, 1}, coldTarget: 1}},
+			{key: 1, hit: true, expected: clockProState[int]{keys: []int{3, 4, 1}, coldTarget: 1}},
+			// Cold keys out of their test period are forgotten when evicted.
+			{write: true, key: 5, evicted: []int{3}, expected: clockProState[int]{
+				keys: []int{4, 1, 5}, test: []int{5}, coldTarget: 1}},
+			{write: true, key: 6, evicted: []int{4}, expected: clockProState[int]{
+				keys: []int{1, 5, 6}, test: []int{5, 6}, coldTarget: 1}},
+			// 1 was requested, it starts a new test period at the head of the list.
+			{write: true, key: 7, evicted: []int{5}, expected: clockProState[int]{
+				keys: []int{5, 6, 1, 7}, test: []int{5, 6, 1, 7}, nonResident: []int{5}, coldTarget: 1}},
+			{key: 1, hit: true, expected: clockProState[int]{
+				keys: []int{5, 6, 1, 7}, test: []int{5, 6, 1, 7}, nonResident: []int{5}, coldTarget: 1}},
+			{write: true, key: 8, evicted: []int{6}, expected: clockProState[int]{
+				keys: []int{5, 6, 1, 7, 8}, test: []int{5, 6, 1, 7, 8}, nonResident: []int{5, 6}, coldTarget: 1}},
+			// handCold finds 1 requested during its test period, so it becomes hot.
+			{write: true, key: 9, evicted: []int{7}, expected: clockProState[int]{
+				keys: []int{5, 6, 7, 8, 1, 9}, hot: []int{1}, test: []int{5, 6, 7, 8, 9}, nonResident: []int{5, 6, 7}, coldTarget: 1}},
+			// 5 was requested during its test period, so the cold target grows. handHot
+			// makes room for 5 by demoting 1 and the test periods it ends shrink it back.
+			{write: true, key: 5, evicted: []int{8}, expected: clockProState[int]{
+				keys: []int{9, 5, 1}, hot: []int{5}, test: []int{9}, coldTarget: 1}},
+		}
+		c := newClockPro[int, int](3)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i, step := range steps {
+			evicted = evicted[:0]
+			if step.write {
+				c.Write(step.key, step.key*10)
+			} else if value, isCacheMiss := c.Read(step.key); isCacheMiss == step.hit || (step.hit && value != step.key*10) {
+				t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+			}
+			if state := c.state(); !equalStates(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("deleted keys are forgotten by the list", func(t *testing.T) {
+		c := newClockPro[int, int](3)
+		for key := 1; key <= 4; key++ {
+			c.Write(key, key*10) // 1 is non-resident
+		}
+		if c.Delete(1) || !c.Delete(2) || c.Delete(2) {
+			t.Fatalf("unexpected deletes, state %#v", c.state())
+		}
+		expected := clockProState[int]{keys: []int{3, 4}, test: []int{3, 4}, coldTarget: 3}
+		if state := c.state(); !equalStates(state, expected) || c.Len() != 2 {
+			t.Fatalf("expected %#v after deletes but got %#v", expected, state)
+		}
+	})
+	t.Run("counters and hands stay consistent", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(5))
+		c := newClockPro[int, int](10)
+		for i := 0; i < 10000; i++ {
+			key := rnd.Intn(30)
+			switch op := rnd.Intn(20); {
+			case op == 0:
+				_ = c.Delete(key)
+			case op == 1:
+				c.Resize(1 + rnd.Intn(15))
+			case op < 10:
+				_, _ = c.Read(key)
+			default:
+				c.Write(key, key)
+			}
+			var hot, cold, nonResident int
+			c.each(func(node *clockNode[int, int]) {
+				switch node.page {
+				case clockProHot:
+					hot++
+				case clockProCold:
+					cold++
+				case clockProNonResident:
+					nonResident++
+				}
+			})
+			if hot != c.numHot || cold != c.numCold || nonResident != c.numNonResident || len(c.hash) != hot+cold+nonResident {
+				t.Fatalf("step %d: counted %d hot, %d cold and %d non-resident keys but got %d, %d and %d",
+					i, hot, cold, nonResident, c.numHot, c.numCold, c.numNonResident)
+			}
+			if c.Len() > c.Capacity() || nonResident > c.Capacity() || hot > c.Capacity()-c.coldTarget {
+				t.Fatalf("step %d: %d hot, %d cold and %d non-resident keys overflow capacity %d",
+					i, hot, cold, nonResident, c.Capacity())
+			}
+		}
+	})
+	t.Run("hit rate beats clock on a loop larger than the cache", func(t *testing.T) {
+		expectHigherHitRate(t, loopTrace(120, 20), 100, CLOCKPro, CLOCK)
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
 	"time"
@@ -13,6 +14,8 @@ func init() {
 }
 
 func main() {
+	opt := flag.Bool("opt", false, "compare each cache to Belady's optimal policy on every workload")
+	flag.Parse()
 	var (
 		// size of the input set
 		m = 1000000
@@ -22,26 +25,110 @@ func main() {
 		k = 1000
 		// input set generated randomly
 		values = generate(n, m)
-		// all the caches under test
-		cacheTypes = []string{ cache.LRU, cache.LFU, cache.MRU, cache.SLRU, cache.LFRU, cache.ARC }
-		hitRate float64
-		missRate float64
+		// input set with a few very popular values
+		zipf = skewed(n, m)
+		// workloads replayed through every cache
+		workloads = []struct{
+			name   string
+			values []int
+		}{
+			{"Random", values},
+			{"Zipf", zipf},
+			// looping over slightly more keys than fit in the cache
+			{fmt.Sprintf("Loop over %d keys", k + k/5), loop(k + k/5, m)},
+		}
+		// all the caches under test, including the ones added with cache.Register
+		cacheTypes = cache.Algorithms()
+		// segmented caches under test with each of the segment ratios
+		segmentedTypes = []string{ cache.SLRU, cache.LFRU }
+		ratios = []float64{ 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9 }
+		// cost of a miss and size of each key, between 1 and 100
+		costs, sizes = weights(n), weights(n)
+		// caches whose capacity is the total size of their entries, see cache.WriteWithCost
+		costAwareTypes = map[string]bool{ cache.GDSF: true, cache.GreedyDual: true }
 	)
-	fmt.Printf("Cache type    Hit rate    Miss rate \n")
-	for _, cacheType := range cacheTypes {
-		c := cache.Factory(cacheType, k)
-		misses := 0
-		for _, value := range values {
-			_, isCacheMiss := c.Read(value)
-			if isCacheMiss {
-				misses += 1
-				c.Write(value, value)
+	if *opt {
+		for _, workload := range workloads {
+			optimal := measure(cache.Factory[int, int](cache.OPT, k, cache.WithTrace(workload.values)), workload.values)
+			fmt.Printf("%s, opt hit rate %2.3f\n", workload.name, optimal)
+			fmt.Printf("Cache type        Hit rate    Gap to opt \n")
+			for _, cacheType := range cacheTypes {
+				hitRate := measure(cache.Factory[int, int](cacheType, k), workload.values)
+				fmt.Printf("%14s    %2.3f      %2.3f\n", cacheType, hitRate, optimal-hitRate)
 			}
+			fmt.Printf("\n")
+		}
+		return
+	}
+	for _, workload := range workloads {
+		fmt.Printf("%s\n", workload.name)
+		fmt.Printf("Cache type        Hit rate    Miss rate \n")
+		for _, cacheType := range cacheTypes {
+			hitRate := measure(cache.Factory[int, int](cacheType, k), workload.values)
+			fmt.Printf("%14s    %2.3f      %2.3f\n", cacheType, hitRate, 100-hitRate)
+		}
+		fmt.Printf("\n")
+	}
+	for _, workload := range workloads {
+		fmt.Printf("%s, by segment ratio\n", workload.name)
+		fmt.Printf("Cache type    Ratio    Hit rate    Miss rate \n")
+		for _, cacheType := range segmentedTypes {
+			for _, ratio := range ratios {
+				hitRate := measure(cache.Factory[int, int](cacheType, k, cache.WithSegmentRatio(ratio)), workload.values)
+				fmt.Printf("%10s    %.1f      %2.3f      %2.3f\n", cacheType, ratio, hitRate, 100-hitRate)
+			}
+		}
+		fmt.Printf("\n")
+	}
+	// Cost aware caches get as much room as the others on average, the mean size being about 50.
+	fmt.Printf("Zipf with costs and sizes\n")
+	fmt.Printf("Cache type        Hit rate    Byte hit rate \n")
+	for _, cacheType := range cacheTypes {
+		size := k
+		if costAwareTypes[cacheType] {
+			size = k * 50
+		}
+		hitRate, byteHitRate := measureWeighted(cache.Factory[int, int](cacheType, size), zipf, costs, sizes)
+		fmt.Printf("%14s    %2.3f      %2.3f\n", cacheType, hitRate, byteHitRate)
+	}
+}
+
+// measure replays the values through the cache, writing the missing ones,
+// and returns the percentage of hits.
+func measure(c cache.Cache[int, int], values []int) float64 {
+	for _, value := range values {
+		_, isCacheMiss := c.Read(value)
+		if isCacheMiss {
+			c.Write(value, value)
+		}
+	}
+	return c.Stats().HitRate() * 100
+}
+
+// measureWeighted replays the values through the cache, writing the missing
+// ones with their cost and size, and returns the percentages of hits and of
+// bytes served by hits.
+func measureWeighted(c cache.Cache[int, int], values []int, costs, sizes []int) (hitRate, byteHitRate float64) {
+	var hitBytes, totalBytes int
+	for _, value := range values {
+		totalBytes += sizes[value]
+		_, isCacheMiss := c.Read(value)
+		if isCacheMiss {
+			c.WriteWithCost(value, value, float64(costs[value]), sizes[value])
+		} else {
+			hitBytes += sizes[value]
 		}
-		hitRate = float64(m - misses) / float64(m) * 100
-		missRate = float64(misses) / float64(m) * 100
-		fmt.Printf("%10s    %2.3f      %2.3f\n", cacheType, hitRate, missRate)
 	}
+	return c.Stats().HitRate() * 100, float64(hitBytes) / float64(totalBytes) * 100
+}
+
+// weights draws a number between 1 and 100 for every value of the input set.
+func weights(cardinality int) []int {
+	out := make([]int, cardinality + 1)
+	for i := range out {
+		out[i] = 1 + rand.Intn(100)
+	}
+	return out
 }
 
 func generate(cardinality, length int) []int {
@@ -51,3 +138,22 @@ func generate(cardinality, length int) []int {
 	}
 	return out
 }
+
+// skewed draws the values from a Zipf distribution, so that a few of them are very popular.
+func skewed(cardinality, length int) []int {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(rand.Int63())), 1.1, 1, uint64(cardinality))
+	out := make([]int, length)
+	for i := 0; i < length; i ++ {
+		out[i] = int(zipf.Uint64())
+	}
+	return out
+}
+
+// loop requests the keys of the set in order, over and over.
+func loop(cardinality, length int) []int {
+	out := make([]int, length)
+	for i := 0; i < length; i ++ {
+		out[i] = i % cardinality
+	}
+	return out
+}
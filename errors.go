@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// UnknownAlgorithmError is returned by New for algorithms it doesn't know how to build.
+type UnknownAlgorithmError struct {
+	Algorithm string
+}
+
+func (e *UnknownAlgorithmError) Error() string {
+	return fmt.Sprintf("unsupported caching algorithm %s", e.Algorithm)
+}
+
+// InvalidSizeError is returned when the capacity requested for a cache is too
+// small for its algorithm, eg. segmented strategies need a slot in each segment.
+type InvalidSizeError struct {
+	Algorithm string
+	Size      int
+	Min       int // smallest size supported by the algorithm with the given options
+}
+
+func (e *InvalidSizeError) Error() string {
+	return fmt.Sprintf("unsupported size %d for %s, the minimum is %d", e.Size, e.Algorithm, e.Min)
+}
+
+// InvalidOptionError is returned when an option is out of range
+// or doesn't match the key and value types of the cache.
+type InvalidOptionError struct {
+	Option string
+	Reason string
+}
+
+func (e *InvalidOptionError) Error() string {
+	return fmt.Sprintf("invalid option %s: %s", e.Option, e.Reason)
+}
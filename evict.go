@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// EvictionReason tells why an entry left the cache.
+type EvictionReason int
+
+const (
+	// EvictionCapacity means the replacement strategy picked the entry to make room for another one.
+	EvictionCapacity EvictionReason = iota
+	// EvictionExpired means the entry outlived its TTL.
+	EvictionExpired
+	// EvictionDeleted means the entry was removed explicitly.
+	EvictionDeleted
+	// EvictionReplaced means a new value was written for the same key.
+	EvictionReplaced
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionCapacity:
+		return "capacity"
+	case EvictionExpired:
+		return "expired"
+	case EvictionDeleted:
+		return "deleted"
+	case EvictionReplaced:
+		return "replaced"
+	default:
+		return fmt.Sprintf("EvictionReason(%d)", int(r))
+	}
+}
+
+// evictNotifier is embedded by all strategies to report the entries they evict.
+// Composite strategies only report entries which leave the cache altogether,
+// not the ones moving between their internal segments.
+type evictNotifier[K comparable, V any] struct {
+	onEvict func(key K, value V)
+}
+
+// SetEvictHandler registers the function called for every evicted entry.
+func (n *evictNotifier[K, V]) SetEvictHandler(fn func(key K, value V)) {
+	n.onEvict = fn
+}
+
+func (n *evictNotifier[K, V]) notifyEvict(key K, value V) {
+	if n.onEvict != nil {
+		n.onEvict(key, value)
+	}
+}
@@ -0,0 +1,27 @@
+package cache
+
+func newFIFO[K comparable, V any](size int) *fifo[K, V] {
+	return &fifo[K, V]{lru: newLRU[K, V](size)}
+}
+
+// fifo implements Policy
+// FIFO evicts the oldest key, regardless of the requests it got since it was
+// written. It's an lru list where hits and updates don't promote keys.
+type fifo[K comparable, V any] struct {
+	*lru[K, V]
+}
+
+func (c *fifo[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	if node, found := c.hash[key]; found {
+		return node.value, false
+	}
+	return value, true
+}
+
+func (c *fifo[K, V]) Write(key K, value V) {
+	if node, found := c.hash[key]; found {
+		node.value = value
+		return
+	}
+	c.lru.Write(key, value)
+}
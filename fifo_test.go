@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFIFO(t *testing.T) {
+	c := newFIFO[int, int](3)
+	evicted := []int{}
+	c.SetEvictHandler(func(key, value int) {
+		evicted = append(evicted, key)
+	})
+	c.Write(1, 10)
+	c.Write(2, 20)
+	c.Write(3, 30)
+	_, _ = c.Read(1)
+	c.Write(2, 21)
+	c.Write(4, 40) // neither the hit on 1 nor the update of 2 save them
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []int{4, 3, 2}) || !reflect.DeepEqual(evicted, []int{1}) {
+		t.Fatalf("unexpected keys %#v or evictions %#v", keys, evicted)
+	}
+	c.Write(5, 50)
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []int{5, 4, 3}) || !reflect.DeepEqual(evicted, []int{1, 2}) {
+		t.Fatalf("unexpected keys %#v or evictions %#v", keys, evicted)
+	}
+	c.Resize(1)
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []int{5}) || !reflect.DeepEqual(evicted, []int{1, 2, 3, 4}) {
+		t.Fatalf("unexpected keys %#v or evictions %#v after shrinking", keys, evicted)
+	}
+}
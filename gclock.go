@@ -0,0 +1,208 @@
+package cache
+
+// gclockMaxCount caps the reference counters of Generalized CLOCK, so that
+// keys which stop being requested are evicted after a few turns of the hand.
+const gclockMaxCount = 3
+
+func newGCLOCK[K comparable, V any](size, maxCount int) *gclock[K, V] {
+	return &gclock[K, V]{
+		clockRing: newClockRing[K, V](),
+		size:      size,
+		maxCount:  maxCount,
+	}
+}
+
+// gclock implements Policy
+// CLOCK, also known as second-chance, keeps the keys in a circular list
+// with a reference bit each. A hit only sets the bit, keys don't move. To
+// make room, the hand sweeps the list, clearing the bits it finds set, and
+// evicts the first key without one. The new key takes the evicted key's
+// place, just behind the hand, so CLOCK approximates LRU.
+//
+// Generalized CLOCK replaces the bit with a counter, incremented on a hit up
+// to maxCount and decremented by the hand, which approximates LFU instead.
+// CLOCK is Generalized CLOCK with a maxCount of one.
+type gclock[K comparable, V any] struct {
+	evictNotifier[K, V]
+	clockRing[K, V]
+	size     int
+	maxCount int
+}
+
+// clockRing is a circular list of keys with a hand, the clock of the CLOCK strategies.
+type clockRing[K comparable, V any] struct {
+	hand *clockNode[K, V]
+	hash map[K]*clockNode[K, V]
+}
+
+// clockNode is a key in the circular list of the CLOCK strategies.
+type clockNode[K comparable, V any] struct {
+	key      K
+	value    V
+	count    int // the reference bit of CLOCK and CLOCK-Pro, the counter of GCLOCK
+	page     clockProPage
+	test     bool // only used by CLOCK-Pro
+	next     *clockNode[K, V]
+	previous *clockNode[K, V]
+}
+
+func (c *gclock[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node, exists := c.hash[key]
+	if !exists {
+		return value, true
+	}
+	c.reference(node)
+	return node.value, false
+}
+
+func (c *gclock[K, V]) Write(key K, value V) {
+	if node, exists := c.hash[key]; exists {
+		node.value = value
+		c.reference(node)
+		return
+	}
+	if len(c.hash) >= c.size {
+		c.evict()
+	}
+	c.insert(&clockNode[K, V]{key: key, value: value})
+}
+
+func (c *gclock[K, V]) Peek(key K) (value V, found bool) {
+	if node, exists := c.hash[key]; exists {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (c *gclock[K, V]) Delete(key K) bool {
+	node, exists := c.hash[key]
+	if !exists {
+		return false
+	}
+	c.remove(node)
+	return true
+}
+
+func (c *gclock[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *gclock[K, V]) Capacity() int {
+	return c.size
+}
+
+// Keys returns the keys in the order the hand will reach them.
+func (c *gclock[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.hash))
+	c.each(func(node *clockNode[K, V]) {
+		keys = append(keys, node.key)
+	})
+	return keys
+}
+
+func (c *gclock[K, V]) Purge() {
+	c.clockRing = newClockRing[K, V]()
+}
+
+func (c *gclock[K, V]) Resize(size int) {
+	c.size = size
+	for len(c.hash) > c.size {
+		c.evict()
+	}
+}
+
+func (c *gclock[K, V]) reference(node *clockNode[K, V]) {
+	if node.count < c.maxCount {
+		node.count++
+	}
+}
+
+// evict sweeps the list from the hand, decrementing the counters,
+// until it finds a key with a counter of zero and removes it.
+func (c *gclock[K, V]) evict() {
+	for c.hand.count > 0 {
+		c.hand.count--
+		c.hand = c.hand.next
+	}
+	node := c.hand
+	c.remove(node)
+	c.notifyEvict(node.key, node.value)
+}
+
+func newClockRing[K comparable, V any]() clockRing[K, V] {
+	return clockRing[K, V]{hash: make(map[K]*clockNode[K, V])}
+}
+
+func (r *clockRing[K, V]) len() int {
+	return len(r.hash)
+}
+
+// insert adds the node just behind the hand, ie. the hand reaches it last.
+func (r *clockRing[K, V]) insert(node *clockNode[K, V]) {
+	r.hash[node.key] = node
+	if r.hand == nil {
+		node.link(nil)
+		r.hand = node
+		return
+	}
+	node.link(r.hand)
+}
+
+// remove takes the node out of the ring, moving the hand forward if it points at it.
+func (r *clockRing[K, V]) remove(node *clockNode[K, V]) {
+	if r.hand == node {
+		r.hand = node.next
+		if r.hand == node {
+			r.hand = nil
+		}
+	}
+	node.unlink()
+	delete(r.hash, node.key)
+}
+
+// each calls fn for every node, starting with the hand.
+func (r *clockRing[K, V]) each(fn func(node *clockNode[K, V])) {
+	if r.hand == nil {
+		return
+	}
+	node := r.hand
+	for {
+		fn(node)
+		if node = node.next; node == r.hand {
+			return
+		}
+	}
+}
+
+// link inserts the node in a circular list just before at,
+// or makes it a list of its own if at is nil.
+func (n *clockNode[K, V]) link(at *clockNode[K, V]) {
+	if at == nil {
+		n.next, n.previous = n, n
+		return
+	}
+	n.next, n.previous = at, at.previous
+	at.previous.next = n
+	at.previous = n
+}
+
+// unlink removes the node from its circular list.
+func (n *clockNode[K, V]) unlink() {
+	n.previous.next = n.next
+	n.next.previous = n.previous
+	n.next, n.previous = nil, nil
+}
+
+type gclockState[K comparable] struct {
+	keys   []K   // from the hand
+	counts []int // the counter of each key
+}
+
+func (c *gclock[K, V]) state() gclockState[K] {
+	s := gclockState[K]{}
+	c.each(func(node *clockNode[K, V]) {
+		s.keys = append(s.keys, node.key)
+		s.counts = append(s.counts, node.count)
+	})
+	return s
+}
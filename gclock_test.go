@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGCLOCK(t *testing.T) {
+	t.Run("clock gives requested keys a second chance", func(t *testing.T) {
+		c := newGCLOCK[int, int](3, 1)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		c.Write(4, 40) // the hand clears the bit of 1 and evicts 2
+		expected := gclockState[int]{keys: []int{3, 1, 4}, counts: []int{0, 0, 0}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) || !reflect.DeepEqual(evicted, []int{2}) {
+			t.Fatalf("unexpected state %#v or evictions %#v", state, evicted)
+		}
+		_, _ = c.Read(3)
+		c.Write(5, 50) // the hand clears the bit of 3 and evicts 1
+		expected = gclockState[int]{keys: []int{4, 3, 5}, counts: []int{0, 0, 0}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) || !reflect.DeepEqual(evicted, []int{2, 1}) {
+			t.Fatalf("unexpected state %#v or evictions %#v", state, evicted)
+		}
+	})
+	t.Run("generalized clock counts requests up to a maximum", func(t *testing.T) {
+		c := newGCLOCK[int, int](3, gclockMaxCount)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		for i := 0; i < 4; i++ {
+			_, _ = c.Read(1)
+		}
+		_, _ = c.Read(2)
+		c.Write(4, 40) // the hand decrements 1 and 2, then evicts 3
+		expected := gclockState[int]{keys: []int{1, 2, 4}, counts: []int{2, 0, 0}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) || !reflect.DeepEqual(evicted, []int{3}) {
+			t.Fatalf("unexpected state %#v or evictions %#v", state, evicted)
+		}
+		c.Write(5, 50) // the hand decrements 1, then evicts 2
+		expected = gclockState[int]{keys: []int{4, 1, 5}, counts: []int{0, 1, 0}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) || !reflect.DeepEqual(evicted, []int{3, 2}) {
+			t.Fatalf("unexpected state %#v or evictions %#v", state, evicted)
+		}
+	})
+	t.Run("deleting the key under the hand moves the hand forward", func(t *testing.T) {
+		c := newGCLOCK[int, int](3, 1)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		if !c.Delete(1) || c.Delete(1) || c.hand.key != 2 {
+			t.Fatalf("unexpected state %#v after deleting the key under the hand", c.state())
+		}
+		if !c.Delete(2) || c.hand != nil || c.Len() != 0 {
+			t.Fatalf("expected an empty clock but got %#v", c.state())
+		}
+		c.Write(3, 30)
+		if !reflect.DeepEqual(c.Keys(), []int{3}) {
+			t.Fatalf("unexpected keys %#v", c.Keys())
+		}
+	})
+	t.Run("shrinking sweeps the hand until the keys fit", func(t *testing.T) {
+		c := newGCLOCK[int, int](4, 1)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for key := 1; key <= 4; key++ {
+			c.Write(key, key*10)
+		}
+		_, _ = c.Read(1)
+		_, _ = c.Read(3)
+		c.Resize(2)
+		if keys := c.Keys(); !reflect.DeepEqual(keys, []int{1, 3}) || !reflect.DeepEqual(evicted, []int{2, 4}) {
+			t.Fatalf("unexpected keys %#v or evictions %#v after shrinking", keys, evicted)
+		}
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/topliceanu/cache
 
-go 1.14
+go 1.24
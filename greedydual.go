@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"container/heap"
+)
+
+func newGreedyDual[K comparable, V any](size int, frequency bool) *greedyDual[K, V] {
+	return &greedyDual[K, V]{
+		hash:      make(map[K]*greedyDualEntry[K, V]),
+		size:      size,
+		frequency: frequency,
+	}
+}
+
+// greedyDual implements Policy
+// Without frequency, it follows GreedyDual as described in "The k-Server Dual
+// and Loose Competitiveness for Paging" by Neal E. Young. With frequency, it
+// follows GreedyDual-Size-Frequency as described in "Enhancement and
+// Validation of Squid's Cache Replacement Policy" by John Dilley, Martin
+// Arlitt and Stéphane Perret.
+//
+// Every key has a priority and the key with the lowest one is evicted. The
+// cache keeps an inflation value, which becomes the priority of each evicted
+// key. A requested key gets the inflation value plus its cost in GreedyDual,
+// or plus frequency * cost / size in GDSF, so keys which are expensive to
+// miss, small or popular stay longer. Because the inflation value grows with
+// every eviction, the keys which haven't been requested for a while end up
+// with the lowest priorities, even if they used to be valuable. When
+// priorities are equal, the least recently used key is evicted.
+//
+// The capacity is the total size of the keys, so keys are evicted until the
+// new one fits. A key larger than the whole cache is not admitted at all,
+// it's reported as evicted right away. Write uses a cost and a size of 1,
+// see WriteWithCost. Negative costs count as 0 and sizes below 1 count as 1.
+type greedyDual[K comparable, V any] struct {
+	evictNotifier[K, V]
+	hash      map[K]*greedyDualEntry[K, V]
+	heap      greedyDualHeap[K, V]
+	size      int     // maximum total size of the keys
+	used      int     // total size of the keys
+	frequency bool    // whether the priority takes the frequency and the size into account, ie. GDSF
+	inflation float64 // priority of the last evicted key
+	clock     int     // logical clock, ticks on every request
+}
+
+type greedyDualEntry[K comparable, V any] struct {
+	key         K
+	value       V
+	cost        float64
+	size        int
+	numRequests int
+	priority    float64
+	lastRequest int // value of the cache's clock when the entry was last requested
+	index       int // index of the entry in the heap
+}
+
+func (c *greedyDual[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	e, found := c.hash[key]
+	if !found {
+		return value, true
+	}
+	c.request(e)
+	return e.value, false
+}
+
+func (c *greedyDual[K, V]) Write(key K, value V) {
+	c.WriteWithCost(key, value, 1, 1)
+}
+
+func (c *greedyDual[K, V]) WriteWithCost(key K, value V, cost float64, size int) {
+	if cost < 0 {
+		cost = 0
+	}
+	if size < 1 {
+		size = 1
+	}
+	if e, found := c.hash[key]; found {
+		c.used += size - e.size
+		e.value, e.cost, e.size = value, cost, size
+		c.request(e)
+		c.fit(0)
+		return
+	}
+	if size > c.size {
+		c.notifyEvict(key, value)
+		return
+	}
+	c.fit(size)
+	e := &greedyDualEntry[K, V]{key: key, value: value, cost: cost, size: size}
+	c.hash[key] = e
+	c.used += size
+	heap.Push(&c.heap, e)
+	c.request(e)
+}
+
+func (c *greedyDual[K, V]) Peek(key K) (value V, found bool) {
+	if e, found := c.hash[key]; found {
+		return e.value, true
+	}
+	return value, false
+}
+
+func (c *greedyDual[K, V]) Delete(key K) bool {
+	e, found := c.hash[key]
+	if !found {
+		return false
+	}
+	heap.Remove(&c.heap, e.index)
+	delete(c.hash, key)
+	c.used -= e.size
+	return true
+}
+
+func (c *greedyDual[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *greedyDual[K, V]) Capacity() int {
+	return c.size
+}
+
+func (c *greedyDual[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.heap))
+	for _, e := range c.heap {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (c *greedyDual[K, V]) Purge() {
+	c.hash = make(map[K]*greedyDualEntry[K, V])
+	c.heap = nil
+	c.used = 0
+	c.inflation = 0
+	c.clock = 0
+}
+
+func (c *greedyDual[K, V]) Resize(size int) {
+	c.size = size
+	c.fit(0)
+}
+
+// request counts a request for the entry and resets its priority.
+func (c *greedyDual[K, V]) request(e *greedyDualEntry[K, V]) {
+	c.clock++
+	e.numRequests++
+	e.lastRequest = c.clock
+	e.priority = c.inflation + e.cost
+	if c.frequency {
+		e.priority = c.inflation + float64(e.numRequests)*e.cost/float64(e.size)
+	}
+	heap.Fix(&c.heap, e.index)
+}
+
+// fit evicts keys until there's room for a new key of the given size.
+func (c *greedyDual[K, V]) fit(size int) {
+	for len(c.heap) > 0 && c.used+size > c.size {
+		c.evict()
+	}
+}
+
+// evict drops the key with the lowest priority and inflates the priority
+// of the keys requested from now on up to it.
+func (c *greedyDual[K, V]) evict() {
+	e := heap.Pop(&c.heap).(*greedyDualEntry[K, V])
+	delete(c.hash, e.key)
+	c.used -= e.size
+	c.inflation = e.priority
+	c.notifyEvict(e.key, e.value)
+}
+
+// greedyDualHeap implements heap.Interface and keeps the entry with the
+// lowest priority at the head of the slice.
+type greedyDualHeap[K comparable, V any] []*greedyDualEntry[K, V]
+
+func (h greedyDualHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h greedyDualHeap[K, V]) Less(i, j int) bool {
+	if h[i].priority == h[j].priority {
+		return h[i].lastRequest < h[j].lastRequest
+	}
+	return h[i].priority < h[j].priority
+}
+
+func (h greedyDualHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *greedyDualHeap[K, V]) Push(x interface{}) {
+	e := x.(*greedyDualEntry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *greedyDualHeap[K, V]) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+type greedyDualState[K comparable] struct {
+	used       int
+	inflation  float64
+	priorities map[K]float64
+}
+
+func (c *greedyDual[K, V]) state() greedyDualState[K] {
+	s := greedyDualState[K]{used: c.used, inflation: c.inflation, priorities: make(map[K]float64)}
+	for key, e := range c.hash {
+		s.priorities[key] = e.priority
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGreedyDual(t *testing.T) {
+	t.Run("gdsf evicts the key with the lowest priority and inflates the others", func(t *testing.T) {
+		type step struct {
+			write    bool
+			key      int
+			cost     float64 // only used for writes
+			size     int     // only used for writes
+			hit      bool    // only checked for reads
+			expected greedyDualState[int]
+			evicted  []int // keys evicted by the step
+		}
+		steps := []step{ // size=4
+			{write: true, key: 1, cost: 4, size: 2, expected: greedyDualState[int]{used: 2, priorities: map[int]float64{1: 2}}},
+			{write: true, key: 2, cost: 1, size: 1, expected: greedyDualState[int]{used: 3, priorities: map[int]float64{1: 2, 2: 1}}},
+			{key: 2, hit: true, expected: greedyDualState[int]{used: 3, priorities: map[int]float64{1: 2, 2: 2}}},
+			{write: true, key: 3, cost: 3, size: 1, expected: greedyDualState[int]{used: 4, priorities: map[int]float64{1: 2, 2: 2, 3: 3}}},
+			// 1 and 2 have the same priority, 1 was requested less recently.
+			{write: true, key: 4, cost: 2, size: 2, evicted: []int{1},
+				expected: greedyDualState[int]{used: 4, inflation: 2, priorities: map[int]float64{2: 2, 3: 3, 4: 3}}},
+			{write: true, key: 5, cost: 1, size: 1, evicted: []int{2},
+				expected: greedyDualState[int]{used: 4, inflation: 2, priorities: map[int]float64{3: 3, 4: 3, 5: 3}}},
+			// 6 needs the whole cache.
+			{write: true, key: 6, cost: 1, size: 4, evicted: []int{3, 4, 5},
+				expected: greedyDualState[int]{used: 4, inflation: 3, priorities: map[int]float64{6: 3.25}}},
+			// 7 doesn't fit at all.
+			{write: true, key: 7, cost: 1, size: 5, evicted: []int{7},
+				expected: greedyDualState[int]{used: 4, inflation: 3, priorities: map[int]float64{6: 3.25}}},
+		}
+		c := newGreedyDual[int, int](4, true)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i, step := range steps {
+			evicted = evicted[:0]
+			if step.write {
+				c.WriteWithCost(step.key, step.key*10, step.cost, step.size)
+			} else if value, isCacheMiss := c.Read(step.key); isCacheMiss == step.hit || (step.hit && value != step.key*10) {
+				t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+			}
+			if state := c.state(); !reflect.DeepEqual(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("greedy dual ignores the frequency and the size", func(t *testing.T) {
+		for _, tc := range []struct {
+			frequency bool
+			evicted   int
+		}{
+			{false, 2}, // 1 is the most expensive to miss
+			{true, 1},  // 2 is smaller and requested more often
+		} {
+			c := newGreedyDual[int, int](3, tc.frequency)
+			evicted := []int{}
+			c.SetEvictHandler(func(key, value int) {
+				evicted = append(evicted, key)
+			})
+			c.WriteWithCost(1, 10, 2, 2)
+			c.WriteWithCost(2, 20, 1, 1)
+			_, _ = c.Read(2)
+			c.WriteWithCost(3, 30, 1, 1)
+			if !reflect.DeepEqual(evicted, []int{tc.evicted}) {
+				t.Fatalf("expected key %d to be evicted with frequency=%t but got %#v", tc.evicted, tc.frequency, evicted)
+			}
+		}
+	})
+	t.Run("updates which make a key larger evict other keys", func(t *testing.T) {
+		c := newGreedyDual[int, int](4, true)
+		c.WriteWithCost(1, 10, 1, 1)
+		c.WriteWithCost(2, 20, 1, 1)
+		c.WriteWithCost(3, 30, 1, 1)
+		c.WriteWithCost(3, 30, 9, 3)
+		expected := greedyDualState[int]{used: 4, inflation: 1, priorities: map[int]float64{2: 1, 3: 6}}
+		if state := c.state(); !reflect.DeepEqual(state, expected) {
+			t.Fatalf("expected key 1 to make room for key 3 but got %#v", state)
+		}
+		if !c.Delete(3) || c.state().used != 1 {
+			t.Fatalf("expected only key 2 to be left after deleting key 3 but got %#v", c.state())
+		}
+	})
+	t.Run("costs and sizes go through the cache", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithConcurrency()}} {
+			var evicted []int
+			opts = append(opts, OnEvict(func(key, value int, reason EvictionReason) {
+				if reason == EvictionCapacity {
+					evicted = append(evicted, key)
+				}
+			}))
+			c := Factory[int, int](GDSF, 10, opts...)
+			c.WriteWithCost(1, 10, 1, 6)
+			c.WriteWithCost(2, 20, 1, 4)
+			c.Write(3, 30)
+			c.WriteWithCost(4, 40, 1, 11)
+			if !reflect.DeepEqual(evicted, []int{1, 4}) || c.Len() != 2 || !c.Contains(2) || !c.Contains(3) {
+				t.Fatalf("unexpected evictions %#v, len=%d", evicted, c.Len())
+			}
+		}
+	})
+	t.Run("other strategies ignore costs and sizes", func(t *testing.T) {
+		for _, algorithm := range Algorithms() {
+			if algorithm == GDSF || algorithm == GreedyDual {
+				continue
+			}
+			c := Factory[int, int](algorithm, 4)
+			c.WriteWithCost(1, 10, 100, 100)
+			if value, isCacheMiss := c.Read(1); isCacheMiss || value != 10 {
+				t.Fatalf("expected %s to store a large key but got value=%d, isCacheMiss=%t", algorithm, value, isCacheMiss)
+			}
+		}
+	})
+	t.Run("gdsf saves more than lru when misses have different costs", func(t *testing.T) {
+		var (
+			rnd   = rand.New(rand.NewSource(7))
+			trace = skewedTrace(7, 1000, 20000, false)
+			costs = make([]float64, 1001)
+		)
+		for key := range costs {
+			costs[key] = float64(1 + rnd.Intn(100))
+		}
+		missCosts := map[string]float64{}
+		for _, algorithm := range []string{LRU, GDSF} {
+			c := Factory[int, int](algorithm, 50)
+			for _, key := range trace {
+				if _, isCacheMiss := c.Read(key); isCacheMiss {
+					missCosts[algorithm] += costs[key]
+					c.WriteWithCost(key, key, costs[key], 1)
+				}
+			}
+		}
+		if missCosts[GDSF] >= missCosts[LRU] {
+			t.Fatalf("expected gdsf to lose less than lru on misses but got %#v", missCosts)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// equalStates compares the states returned by the strategies' state methods,
+// treating nil and empty lists of keys as equal.
+func equalStates(a, b interface{}) bool {
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func equalValues(a, b reflect.Value) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Int:
+		return a.Int() == b.Int()
+	case reflect.Float64:
+		return a.Float() == b.Float()
+	default:
+		panic(fmt.Sprintf("equalStates doesn't support %s", a.Type()))
+	}
+}
+
+// skewedTrace draws length keys out of n from a Zipf distribution. With
+// scans, a fifth of the requests are scans of keys requested only once.
+func skewedTrace(seed int64, n, length int, scans bool) []int {
+	var (
+		rnd   = rand.New(rand.NewSource(seed))
+		zipf  = rand.NewZipf(rnd, 1.1, 1, uint64(n))
+		trace = make([]int, length)
+	)
+	for i := range trace {
+		if scans && i%1000 < 200 {
+			trace[i] = length + i
+		} else {
+			trace[i] = int(zipf.Uint64())
+		}
+	}
+	return trace
+}
+
+// loopTrace requests the keys from 0 to n-1 in order, rounds times.
+func loopTrace(n, rounds int) []int {
+	trace := make([]int, 0, n*rounds)
+	for round := 0; round < rounds; round++ {
+		for key := 0; key < n; key++ {
+			trace = append(trace, key)
+		}
+	}
+	return trace
+}
+
+// scanTrace requests the same hot keys, then scans as many new keys, rounds times.
+func scanTrace(hot, scanned, rounds int) []int {
+	trace := make([]int, 0, (hot+scanned)*rounds)
+	next := hot
+	for round := 0; round < rounds; round++ {
+		for key := 0; key < hot; key++ {
+			trace = append(trace, key)
+		}
+		for i := 0; i < scanned; i++ {
+			trace = append(trace, next)
+			next++
+		}
+	}
+	return trace
+}
+
+// hitRate replays the trace through a new cache, writing the keys it
+// misses, and returns the cache's hit rate.
+func hitRate(algorithm string, size int, trace []int, opts ...Option) float64 {
+	c := Factory[int, int](algorithm, size, opts...)
+	for _, key := range trace {
+		if _, isCacheMiss := c.Read(key); isCacheMiss {
+			c.Write(key, key)
+		}
+	}
+	return c.Stats().HitRate()
+}
+
+// expectHigherHitRate fails the test unless the algorithm's hit rate on the
+// trace is higher than the ones of all the others.
+func expectHigherHitRate(t *testing.T, trace []int, size int, algorithm string, others ...string) {
+	t.Helper()
+	expected := hitRate(algorithm, size, trace)
+	for _, other := range others {
+		if actual := hitRate(other, size, trace); expected <= actual {
+			t.Fatalf("expected %s to beat %s but got hit rates %f and %f", algorithm, other, expected, actual)
+		}
+	}
+}
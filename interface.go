@@ -2,58 +2,278 @@ package cache
 
 import (
 	"fmt"
+	"time"
 )
 
 // Cache is the main interface implemented by all strageties in this project.
-type Cache interface {
-	Read(key int) (value int, isCacheMiss bool)
-	Write(key, value int)
+// Keys can be of any comparable type and values of any type.
+type Cache[K comparable, V any] interface {
+	Read(key K) (value V, isCacheMiss bool)
+	// Write stores the value using the default TTL, see WithTTL.
+	Write(key K, value V)
+	// WriteWithTTL stores a value which is reported as a cache miss once ttl
+	// has passed. A ttl of zero means the value never expires.
+	WriteWithTTL(key K, value V, ttl time.Duration)
+	// WriteWithCost stores the value using the default TTL, along with the
+	// cost of a miss, eg. the time it takes to fetch the value, and its size.
+	// Only GDSF and GreedyDual take them into account, their capacity is the
+	// total size of the entries they hold. Other strategies treat it like
+	// Write, which uses a cost and a size of 1.
+	WriteWithCost(key K, value V, cost float64, size int)
+	// Stats returns the cache's counters accumulated since creation or the last ResetStats.
+	Stats() Stats
+	// ResetStats zeroes the counters, eg. to report stats over time windows.
+	ResetStats()
+	// Delete removes the key from the cache. It returns false if the key was not there.
+	Delete(key K) bool
+	// Peek reads the value of a key without promoting it or counting the request.
+	Peek(key K) (value V, found bool)
+	// Contains tells whether the key is in the cache, without promoting it.
+	Contains(key K) bool
+	// Len returns the number of entries in the cache.
+	Len() int
+	// Capacity returns the maximum number of entries the cache can hold,
+	// or their maximum total size for GDSF and GreedyDual.
+	Capacity() int
+	// Purge removes all entries from the cache.
+	Purge()
+	// Resize changes the capacity of the cache. When shrinking, entries are
+	// evicted in the order chosen by the strategy until the cache fits.
+	// It returns an *InvalidSizeError if size is too small for the strategy.
+	Resize(size int) error
+}
+
+// Policy is implemented by all the replacement strategies. Entry expiration,
+// eviction callbacks and stats are handled on top of it, in the cache type,
+// so that they work the same way for all strategies. Implement it to plug
+// a custom strategy in with Register. Strategies which take the cost and the
+// size of entries into account also implement
+// WriteWithCost(key K, value V, cost float64, size int).
+//
+// Read has no value to admit on a miss, so the Write which usually follows
+// the miss is the request which admits the key. Strategies which remember
+// evicted keys, eg. in ghost lists, handle a request for such a key there.
+type Policy[K comparable, V any] interface {
+	Read(key K) (value V, isCacheMiss bool)
+	Write(key K, value V)
+	// Peek returns the value for key without counting it as an access.
+	Peek(key K) (value V, found bool)
+	// Delete removes key from the cache, it returns false if it wasn't there.
+	// A key which is only remembered after its eviction is forgotten too, so
+	// writing it again doesn't count as a request for a recently evicted key.
+	Delete(key K) bool
+	// Len returns the number of entries currently in the cache.
+	Len() int
+	// Capacity returns the maximum number of entries the policy can hold.
+	Capacity() int
+	// Keys returns all the keys in the cache, in no particular order.
+	Keys() []K
+	// Purge removes all entries and resets the policy to its initial state.
+	Purge()
+	// Resize changes the capacity, evicting entries if they don't fit anymore.
+	Resize(size int)
+	// SetEvictHandler registers a function to call whenever the policy evicts
+	// an entry to make room, ie. on Write and Resize but not on Delete or Purge.
+	SetEvictHandler(fn func(key K, value V))
+}
+
+// costAware is implemented by the strategies which receive the cost and the
+// size given to Cache.WriteWithCost.
+type costAware[K comparable, V any] interface {
+	WriteWithCost(key K, value V, cost float64, size int)
 }
 
 // iCache is an internal interface for cache implementations to expose the data structures used.
 // It's helpful for combining different caches into more complex algorithms, like SLRU, LFRU or AR.
 // It's only for documentation purposes, cache implementations will return, for convenience,
 // their respective node types, not interfaces{}.
-type iCache interface {
+type iCache[K comparable, V any] interface {
 	// read and promote if cache hit
-	read(key int) (node interface{})
+	read(key K) (node interface{})
 	// write new page or update existing one. Either case, promote.
-	write(key, value int) (node, evicted interface{})
+	write(key K, value V) (node, evicted interface{})
 	// remove a page by key. Nothing happens if key is not found.
-	remove(key int) (node interface{})
+	remove(key K) (node interface{})
 	// expose cache's internal state. Should only be used in tests!
 	state() interface{}
 }
 
 // Supress the linter
-var _ iCache
+var _ iCache[int, int]
 
 const (
 	// Cache replacement strategies
-	LRU  = "cache-lru"
-	LFU  = "cache-lfu"
-	MRU  = "cache-mru"
-	SLRU = "cache-slru"
-	LFRU = "cache-lfru"
-	ARC  = "cache-arc"
+	LRU        = "cache-lru"
+	LFU        = "cache-lfu"
+	LFUList    = "cache-lfu-list" // LFU with constant time operations
+	MRU        = "cache-mru"
+	SLRU       = "cache-slru"
+	LFRU       = "cache-lfru"
+	ARC        = "cache-arc"
+	SLRUN      = "cache-slru-n" // SLRU with any number of segments, see WithSegments
+	WTinyLFU   = "cache-w-tinylfu"
+	TwoQ       = "cache-2q" // full 2Q, see WithTwoQueueRatios
+	LIRS       = "cache-lirs"
+	CLOCK      = "cache-clock"
+	GCLOCK     = "cache-gclock" // Generalized CLOCK, with reference counters
+	CLOCKPro   = "cache-clock-pro"
+	CAR        = "cache-car"
+	S3FIFO     = "cache-s3-fifo"
+	SIEVE      = "cache-sieve"
+	FIFO       = "cache-fifo"
+	Random     = "cache-random"      // see WithSeed
+	SampledLRU = "cache-sampled-lru" // evicts the least recently used of a few random keys, see WithSamples
+	SampledLFU = "cache-sampled-lfu" // evicts the least frequently used of a few random keys, see WithSamples
+	OPT        = "cache-opt"         // Belady's optimal policy for offline simulations, see WithTrace
+	LRUK       = "cache-lru-k"       // see WithLRUK
+	GDSF       = "cache-gdsf"        // GreedyDual-Size-Frequency, see WriteWithCost
+	GreedyDual = "cache-greedy-dual" // see WriteWithCost
 )
 
-// Factory produces instances of the requested cache replacement strategy.
-func Factory(algorithm string, size int) Cache {
+// New produces an instance of the requested cache replacement strategy
+// for keys of type K and values of type V, configured with the given options.
+// It returns an *UnknownAlgorithmError for algorithms it doesn't support,
+// an *InvalidSizeError if size is too small for the algorithm and an
+// *InvalidOptionError if any of the options is invalid.
+func New[K comparable, V any](algorithm string, size int, opts ...Option) (Cache[K, V], error) {
+	s := newSettings(opts)
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := evictHandler[K, V](s); err != nil {
+		return nil, err
+	}
+	if _, err := traceOf[K](algorithm, s); err != nil {
+		return nil, err
+	}
+	smallest, err := minSize(algorithm, s)
+	if err != nil {
+		return nil, err
+	}
+	if size < smallest {
+		return nil, &InvalidSizeError{Algorithm: algorithm, Size: size, Min: smallest}
+	}
+	if s.shards > 1 {
+		return newSharded[K, V](algorithm, size, smallest, s), nil
+	}
+	var c Cache[K, V] = newFrontend[K, V](algorithm, size, smallest, s)
+	if s.concurrent {
+		c = newSynchronized(c)
+	}
+	return c, nil
+}
+
+// Factory is like New but it panics if the cache can't be built.
+func Factory[K comparable, V any](algorithm string, size int, opts ...Option) Cache[K, V] {
+	c, err := New[K, V](algorithm, size, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// minSize returns the smallest capacity supported by the algorithm with the given settings.
+func minSize(algorithm string, s *settings) (int, error) {
+	if _, found := lookup(algorithm); !found {
+		return 0, &UnknownAlgorithmError{Algorithm: algorithm}
+	}
+	switch algorithm {
+	case SLRU, LFRU:
+		// Both segments need at least one slot.
+		return smallestSize(func(size int) bool {
+			first, second := splitSegments(size, s.segmentRatio)
+			return first > 0 && second > 0
+		}), nil
+	case SLRUN:
+		return s.segments, nil
+	case WTinyLFU:
+		// The window and both segments of the main region need at least one slot.
+		return smallestSize(func(size int) bool {
+			window, main := splitWTinyLFU(size)
+			protected, probation := splitSegments(main, wTinyLFUProtectedRatio)
+			return window > 0 && protected > 0 && probation > 0
+		}), nil
+	default:
+		return 1, nil
+	}
+}
+
+// smallestSize returns the first size which fits the strategy. Sizes which fit
+// must be contiguous, ie. all sizes larger than one which fits must fit too.
+func smallestSize(fits func(size int) bool) int {
+	size := 1
+	for !fits(size) {
+		size++
+	}
+	return size
+}
+
+// newFrontend builds the strategy and wraps it with the features common to all strategies.
+// The algorithm and size must have been checked with minSize.
+func newFrontend[K comparable, V any](algorithm string, size, smallest int, s *settings) *cache[K, V] {
+	c := newCache(newStrategy[K, *entry[K, V]](algorithm, size, s), s)
+	c.algorithm, c.minSize = algorithm, smallest
+	return c
+}
+
+func newStrategy[K comparable, V any](algorithm string, size int, s *settings) Policy[K, V] {
 	switch algorithm {
 	case LRU:
-		return newLRU(size)
+		return newLRU[K, V](size)
 	case MRU:
-		return newMRU(size)
+		return newMRU[K, V](size)
 	case LFU:
-		return newLFU(size)
+		return newLFU[K, V](size)
+	case LFUList:
+		return newLFUList[K, V](size)
 	case SLRU:
-		return newSLRU(size)
+		return newSLRU[K, V](size, s.segmentRatio)
 	case LFRU:
-		return newLFRU(size)
+		return newLFRU[K, V](size, s.segmentRatio)
 	case ARC:
-		return newARC(size)
+		return newARC[K, V](size)
+	case SLRUN:
+		return newSLRUN[K, V](size, s.segments)
+	case WTinyLFU:
+		return newWTinyLFU[K, V](size)
+	case TwoQ:
+		return newTwoQueue[K, V](size, s.kin, s.kout)
+	case LIRS:
+		return newLIRS[K, V](size)
+	case CLOCK:
+		return newGCLOCK[K, V](size, 1)
+	case GCLOCK:
+		return newGCLOCK[K, V](size, gclockMaxCount)
+	case CLOCKPro:
+		return newClockPro[K, V](size)
+	case CAR:
+		return newCAR[K, V](size)
+	case S3FIFO:
+		return newS3FIFO[K, V](size)
+	case SIEVE:
+		return newSIEVE[K, V](size)
+	case FIFO:
+		return newFIFO[K, V](size)
+	case Random:
+		return newRandom[K, V](size, 1, sampleByRecency, s.seed)
+	case SampledLRU:
+		return newRandom[K, V](size, s.samples, sampleByRecency, s.seed)
+	case SampledLFU:
+		return newRandom[K, V](size, s.samples, sampleByFrequency, s.seed)
+	case LRUK:
+		return newLRUK[K, V](size, s.k, s.correlatedPeriod)
+	case GDSF:
+		return newGreedyDual[K, V](size, true)
+	case GreedyDual:
+		return newGreedyDual[K, V](size, false)
+	case OPT:
+		trace, _ := traceOf[K](algorithm, s)
+		return newOPT[K, V](size, trace)
 	default:
-		panic(fmt.Sprintf("unsupported caching algorithm %s", algorithm))
+		constructor, found := lookup(algorithm)
+		if !found || constructor == nil {
+			panic(fmt.Sprintf("unsupported caching algorithm %s", algorithm))
+		}
+		return adapter[K, V]{policy: constructor(size)}
 	}
 }
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFactory(t *testing.T) {
+	type point struct {
+		x, y int
+	}
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" supports non-int keys and values", func(t *testing.T) {
+			c := Factory[string, point](algorithm, 4)
+			c.Write("origin", point{0, 0})
+			c.Write("unit", point{1, 1})
+			value, isCacheMiss := c.Read("unit")
+			if isCacheMiss || value != (point{1, 1}) {
+				t.Fatalf("expected to read key unit but got value=%#v, isCacheMiss=%t", value, isCacheMiss)
+			}
+			value, isCacheMiss = c.Read("missing")
+			if !isCacheMiss || value != (point{}) {
+				t.Fatalf("expected a cache miss with a zero value but got value=%#v, isCacheMiss=%t", value, isCacheMiss)
+			}
+		})
+	}
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" supports the key management api", func(t *testing.T) {
+			c := Factory[int, int](algorithm, 4)
+			if c.Capacity() != 4 || c.Len() != 0 {
+				t.Fatalf("unexpected capacity=%d, len=%d for an empty cache", c.Capacity(), c.Len())
+			}
+			c.Write(1, 10)
+			c.Write(2, 20)
+			if value, found := c.Peek(1); !found || value != 10 || !c.Contains(1) || c.Contains(3) {
+				t.Fatalf("unexpected peek for key 1: value=%d, found=%t", value, found)
+			}
+			if c.Len() != 2 {
+				t.Fatalf("expected 2 entries but got %d", c.Len())
+			}
+			if !c.Delete(1) || c.Delete(1) || c.Contains(1) || c.Len() != 1 {
+				t.Fatalf("expected key 1 to be deleted exactly once, len=%d", c.Len())
+			}
+			if _, isCacheMiss := c.Read(1); !isCacheMiss {
+				t.Fatal("expected a cache miss for a deleted key")
+			}
+			c.Purge()
+			if c.Len() != 0 || c.Contains(2) || c.Capacity() != 4 {
+				t.Fatalf("expected an empty cache after purge, len=%d", c.Len())
+			}
+			c.Write(3, 30)
+			if value, isCacheMiss := c.Read(3); isCacheMiss || value != 30 {
+				t.Fatalf("expected the cache to be usable after purge but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+			}
+		})
+	}
+	t.Run("peek does not promote keys", func(t *testing.T) {
+		c := Factory[int, int](LRU, 2)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		_, _ = c.Peek(1)
+		c.Write(3, 30)
+		if c.Contains(1) || !c.Contains(2) {
+			t.Fatal("expected key 1 to be evicted as the least recently used")
+		}
+	})
+	t.Run("peek does not evict from mru", func(t *testing.T) {
+		c := Factory[int, int](MRU, 2)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		if value, found := c.Peek(2); !found || value != 20 || !c.Contains(2) || c.Len() != 2 {
+			t.Fatalf("expected key 2 to stay in the cache after a peek, len=%d", c.Len())
+		}
+	})
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" can be resized at runtime", func(t *testing.T) {
+			var (
+				evictions int
+				c         = Factory[int, int](algorithm, 8, OnEvict(func(key, value int, reason EvictionReason) {
+					evictions++
+				}))
+			)
+			for key := 0; key < 32; key++ {
+				c.Write(key, key)
+				_, _ = c.Read(key)
+			}
+			smallest, _ := minSize(algorithm, newSettings(nil))
+			shrunk := max(3, smallest)
+			if err := c.Resize(shrunk); err != nil {
+				t.Fatalf("unexpected error shrinking the cache: %v", err)
+			}
+			if c.Capacity() != shrunk || c.Len() > shrunk || c.Len() != 32-evictions {
+				t.Fatalf("unexpected capacity=%d, len=%d, evictions=%d after shrinking", c.Capacity(), c.Len(), evictions)
+			}
+			if err := c.Resize(16); err != nil {
+				t.Fatalf("unexpected error growing the cache: %v", err)
+			}
+			for key := 0; key < 64; key++ {
+				c.Write(key, key)
+				_, _ = c.Read(key)
+			}
+			if c.Capacity() != 16 || c.Len() > 16 || c.Len() != 96-evictions {
+				t.Fatalf("unexpected capacity=%d, len=%d, evictions=%d after growing", c.Capacity(), c.Len(), evictions)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("unknown algorithms are rejected", func(t *testing.T) {
+		c, err := New[int, int]("cache-unknown", 4)
+		var unknown *UnknownAlgorithmError
+		if c != nil || !errors.As(err, &unknown) || unknown.Algorithm != "cache-unknown" {
+			t.Fatalf("expected an unknown algorithm error but got %v", err)
+		}
+	})
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" rejects sizes which are too small", func(t *testing.T) {
+			for _, size := range []int{-1, 0} {
+				c, err := New[int, int](algorithm, size)
+				var invalid *InvalidSizeError
+				if c != nil || !errors.As(err, &invalid) || invalid.Size != size || invalid.Min < 1 {
+					t.Fatalf("expected an invalid size error for size %d but got %v", size, err)
+				}
+			}
+			smallest, _ := minSize(algorithm, newSettings(nil))
+			c, err := New[int, int](algorithm, smallest)
+			if err != nil {
+				t.Fatalf("unexpected error for the minimum size %d: %v", smallest, err)
+			}
+			var invalid *InvalidSizeError
+			if err := c.Resize(smallest - 1); !errors.As(err, &invalid) || invalid.Min != smallest || c.Capacity() != smallest {
+				t.Fatalf("expected resizing below the minimum size to fail but got %v", err)
+			}
+		})
+	}
+	t.Run("segmented caches need a slot in each segment", func(t *testing.T) {
+		for _, tc := range []struct {
+			algorithm string
+			ratio     float64
+			min       int
+		}{
+			{SLRU, 0.5, 2},
+			{LFRU, 0.5, 2},
+			{SLRU, 0.8, 3},
+			{LFRU, 0.2, 3},
+			{SLRU, 0.9, 6},
+		} {
+			var invalid *InvalidSizeError
+			if _, err := New[int, int](tc.algorithm, tc.min-1, WithSegmentRatio(tc.ratio)); !errors.As(err, &invalid) || invalid.Min != tc.min {
+				t.Fatalf("expected %s with ratio %v to need %d slots but got %v", tc.algorithm, tc.ratio, tc.min, err)
+			}
+			if _, err := New[int, int](tc.algorithm, tc.min, WithSegmentRatio(tc.ratio)); err != nil {
+				t.Fatalf("unexpected error for %s with ratio %v: %v", tc.algorithm, tc.ratio, err)
+			}
+		}
+	})
+	t.Run("slru-n needs a slot in each of its segments", func(t *testing.T) {
+		var invalid *InvalidSizeError
+		if _, err := New[int, int](SLRUN, 3, WithSegments(4)); !errors.As(err, &invalid) || invalid.Min != 4 {
+			t.Fatalf("expected 4 segments to need 4 slots but got %v", err)
+		}
+		c, err := New[int, int](SLRUN, 8, WithSegments(4))
+		if err != nil || c.Capacity() != 8 || len(c.Stats().Segments) != 4 {
+			t.Fatalf("unexpected cache with 4 segments: %v", err)
+		}
+	})
+	t.Run("invalid options are rejected", func(t *testing.T) {
+		for name, opt := range map[string]Option{
+			"WithSegmentRatio":   WithSegmentRatio(1),
+			"WithShards":         WithShards(-1),
+			"WithTTL":            WithTTL(-time.Second),
+			"WithClock":          WithClock(nil),
+			"WithSegments":       WithSegments(0),
+			"WithTwoQueueRatios": WithTwoQueueRatios(0.25, 0),
+			"WithSamples":        WithSamples(0),
+			"WithTrace":          WithTrace([]string{"a"}),
+			"WithLRUK":           WithLRUK(0, 0),
+			"OnEvict":            OnEvict(func(key string, value int, reason EvictionReason) {}),
+		} {
+			c, err := New[int, int](SLRU, 4, opt)
+			var invalid *InvalidOptionError
+			if c != nil || !errors.As(err, &invalid) || invalid.Option != name {
+				t.Fatalf("expected option %s to be rejected but got %v", name, err)
+			}
+		}
+	})
+	t.Run("factory panics with the error returned by new", func(t *testing.T) {
+		defer func() {
+			if _, ok := recover().(*InvalidSizeError); !ok {
+				t.Fatal("expected a panic with an invalid size error")
+			}
+		}()
+		_ = Factory[int, int](ARC, 0)
+	})
+}
@@ -1,20 +1,34 @@
 package cache
 
-// lfru implements Cache
-type lfru struct {
-	privileged   *lru
-	unprivileged *lfu
+// lfru implements Policy
+// The unprivileged section can be any LFU implementation, eg. lfu or lfuList.
+type lfru[K comparable, V any] struct {
+	evictNotifier[K, V]
+	privileged   *lru[K, V]
+	unprivileged Policy[K, V]
+	ratio        float64 // share of the capacity given to privileged
 }
 
-func newLFRU(size int) *lfru {
-	first, second := (size+1)/2, size/2
-	return &lfru{
-		privileged:   newLRU(first),
-		unprivileged: newLFU(second),
+func newLFRU[K comparable, V any](size int, ratio float64) *lfru[K, V] {
+	return newLFRUWith(size, ratio, func(size int) Policy[K, V] {
+		return newLFU[K, V](size)
+	})
+}
+
+// newLFRUWith uses newUnprivileged to build the unprivileged section of the cache.
+func newLFRUWith[K comparable, V any](size int, ratio float64, newUnprivileged func(size int) Policy[K, V]) *lfru[K, V] {
+	first, second := splitSegments(size, ratio)
+	c := &lfru[K, V]{
+		privileged:   newLRU[K, V](first),
+		unprivileged: newUnprivileged(second),
+		ratio:        ratio,
 	}
+	// Keys evicted from unprivileged leave the cache.
+	c.unprivileged.SetEvictHandler(c.notifyEvict)
+	return c
 }
 
-func (c *lfru) Read(key int) (int, bool) {
+func (c *lfru[K, V]) Read(key K) (V, bool) {
 	// check privileged, if there, read and promote.
 	value, cacheMiss := c.privileged.Read(key)
 	if !cacheMiss {
@@ -23,10 +37,10 @@ func (c *lfru) Read(key int) (int, bool) {
 	// check unprivileged, if not there, cache miss.
 	value, cacheMiss = c.unprivileged.Read(key)
 	if cacheMiss {
-		return 0, true
+		return value, true
 	}
 	// otherwise  delete from unpriviledged, insert to privileged, handle overflow.
-	_ = c.unprivileged.remove(key)
+	_ = c.unprivileged.Delete(key)
 	_, evicted := c.privileged.write(key, value)
 	if evicted != nil {
 		c.unprivileged.Write(evicted.key, evicted.value)
@@ -34,7 +48,7 @@ func (c *lfru) Read(key int) (int, bool) {
 	return value, false
 }
 
-func (c *lfru) Write(key, value int) {
+func (c *lfru[K, V]) Write(key K, value V) {
 	// check privileged, if there, update and promote
 	pnode := c.privileged.read(key)
 	if pnode != nil {
@@ -42,16 +56,54 @@ func (c *lfru) Write(key, value int) {
 		return
 	}
 	// check unprivileged, if not there, insert and evict potential overflow.
-	unode := c.unprivileged.read(key)
-	if unode == nil {
+	if _, found := c.unprivileged.Peek(key); !found {
 		c.unprivileged.Write(key, value)
 		return
 	}
 	// otherwise delete from unprivileged, insert into privileged,
 	// then move potential node evicted from privileged into unprivileged.
-	_ = c.unprivileged.remove(key)
+	_ = c.unprivileged.Delete(key)
 	_, evicted := c.privileged.write(key, value)
 	if evicted != nil {
 		c.unprivileged.Write(evicted.key, evicted.value)
 	}
 }
+
+func (c *lfru[K, V]) Peek(key K) (value V, found bool) {
+	if value, found = c.privileged.Peek(key); found {
+		return value, true
+	}
+	return c.unprivileged.Peek(key)
+}
+
+func (c *lfru[K, V]) Delete(key K) bool {
+	return c.privileged.Delete(key) || c.unprivileged.Delete(key)
+}
+
+func (c *lfru[K, V]) Len() int {
+	return c.privileged.Len() + c.unprivileged.Len()
+}
+
+func (c *lfru[K, V]) Capacity() int {
+	return c.privileged.Capacity() + c.unprivileged.Capacity()
+}
+
+func (c *lfru[K, V]) Keys() []K {
+	return append(c.privileged.Keys(), c.unprivileged.Keys()...)
+}
+
+func (c *lfru[K, V]) Purge() {
+	c.privileged.Purge()
+	c.unprivileged.Purge()
+}
+
+// Resize splits the new size between the sections. When shrinking, keys
+// overflowing from privileged are moved into unprivileged, which evicts
+// whatever doesn't fit in anymore.
+func (c *lfru[K, V]) Resize(size int) {
+	first, second := splitSegments(size, c.ratio)
+	c.unprivileged.Resize(second)
+	for _, node := range c.privileged.resize(first) {
+		c.unprivileged.Write(node.key, node.value)
+	}
+}
@@ -6,33 +6,34 @@ import (
 
 func TestLFRU(t *testing.T) {
 	t.Run("cache state should be correct for a cache with two elements in each side", func(t *testing.T) {
-		c := newLFRU(4)
+		c := newLFRU[int, int](4, defaultSegmentRatio)
+		unprivileged := c.unprivileged.(*lfu[int, int])
 		c.Write(1, 10)
-		if len(c.privileged.hash) != 0 || len(c.unprivileged.hash) != 1 ||
-			c.unprivileged.hash[1].key != 1 || c.unprivileged.hash[1].value != 10 ||
-			c.unprivileged.hash[1].numRequests != 1 || c.unprivileged.hash[1].index != 0 {
+		if len(c.privileged.hash) != 0 || len(unprivileged.hash) != 1 ||
+			unprivileged.hash[1].key != 1 || unprivileged.hash[1].value != 10 ||
+			unprivileged.hash[1].numRequests != 1 || unprivileged.hash[1].index != 0 {
 			t.Fatalf("incorrect cache state after first write: %#v", c.unprivileged)
 		}
 		c.Write(2, 20)
-		if len(c.privileged.hash) != 0 || len(c.unprivileged.hash) != 2 ||
-			c.unprivileged.hash[1].key != 1 || c.unprivileged.hash[1].value != 10 ||
-			c.unprivileged.hash[1].numRequests != 1 || c.unprivileged.hash[1].index != 0 ||
-			c.unprivileged.hash[2].key != 2 || c.unprivileged.hash[2].value != 20 ||
-			c.unprivileged.hash[2].numRequests != 1 || c.unprivileged.hash[2].index != 1 {
+		if len(c.privileged.hash) != 0 || len(unprivileged.hash) != 2 ||
+			unprivileged.hash[1].key != 1 || unprivileged.hash[1].value != 10 ||
+			unprivileged.hash[1].numRequests != 1 || unprivileged.hash[1].index != 0 ||
+			unprivileged.hash[2].key != 2 || unprivileged.hash[2].value != 20 ||
+			unprivileged.hash[2].numRequests != 1 || unprivileged.hash[2].index != 1 {
 			t.Fatalf("incorrect cache state after the second write: %#v", c.unprivileged)
 		}
 		value, cacheMiss := c.Read(2)
 		if value != 20 || cacheMiss != false ||
-			len(c.privileged.hash) != 1 || len(c.unprivileged.hash) != 1 ||
+			len(c.privileged.hash) != 1 || len(unprivileged.hash) != 1 ||
 			c.privileged.hash[2].key != 2 || c.privileged.hash[2].value != 20 ||
 			c.privileged.hash[2].next != nil || c.privileged.hash[2].previous != nil ||
-			c.unprivileged.hash[1].key != 1 || c.unprivileged.hash[1].value != 10 ||
-			c.unprivileged.hash[1].numRequests != 1 || c.unprivileged.hash[1].index != 0 {
+			unprivileged.hash[1].key != 1 || unprivileged.hash[1].value != 10 ||
+			unprivileged.hash[1].numRequests != 1 || unprivileged.hash[1].index != 0 {
 			t.Fatalf("incorrect cache state after the a read: %#v, %#v", c.privileged, c.unprivileged)
 		}
 	})
 	t.Run("cache state after multiple evictions and promotions is correct", func(t *testing.T) {
-		c := newLFRU(4)
+		c := newLFRU[int, int](4, defaultSegmentRatio)
 		c.Write(1, 10)                // (_, 1); (_, _)
 		c.Write(2, 20)                // (2, 1); (_, _)
 		c.Write(3, 30)                // (3, 1); (_, _)
@@ -45,4 +46,34 @@ func TestLFRU(t *testing.T) {
 			t.Fatalf("failed to get the correct value after a read: %#v", c.unprivileged)
 		}
 	})
+	t.Run("unprivileged section can be a constant time LFU", func(t *testing.T) {
+		c := newLFRUWith(4, defaultSegmentRatio, func(size int) Policy[int, int] {
+			return newLFUList[int, int](size)
+		})
+		c.Write(1, 10)
+		c.Write(2, 20)
+		_, _ = c.Read(1) // (_, 1); (_, 2)
+		c.Write(3, 30)   // (_, 1); (2, 3)
+		_, _ = c.Read(3) // (3, 1); (_, 2)
+		c.Write(4, 40)   // (3, 1); (2, 4)
+		unprivileged := c.unprivileged.(*lfuList[int, int])
+		if len(c.privileged.hash) != 2 || len(unprivileged.hash) != 2 ||
+			c.privileged.hash[1] == nil || c.privileged.hash[3] == nil ||
+			unprivileged.hash[2] == nil || unprivileged.hash[4] == nil {
+			t.Fatalf("unexpected cache state: %#v, %#v", c.privileged.state(), unprivileged.state())
+		}
+		if value, cacheMiss := c.Read(4); value != 40 || cacheMiss {
+			t.Fatalf("failed to read from the unprivileged section: value=%d, cacheMiss=%t", value, cacheMiss)
+		}
+	})
+	t.Run("sections are split according to the ratio", func(t *testing.T) {
+		c := newLFRU[int, int](10, 0.3)
+		if c.privileged.size != 3 || c.unprivileged.Capacity() != 7 {
+			t.Fatalf("unexpected section sizes privileged=%d, unprivileged=%d", c.privileged.size, c.unprivileged.Capacity())
+		}
+		c.Resize(4)
+		if c.privileged.size != 1 || c.unprivileged.Capacity() != 3 {
+			t.Fatalf("unexpected section sizes after resize privileged=%d, unprivileged=%d", c.privileged.size, c.unprivileged.Capacity())
+		}
+	})
 }
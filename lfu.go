@@ -1,43 +1,90 @@
 package cache
 
-type lfuNode struct {
-	key         int
-	value       int
+type lfuNode[K comparable, V any] struct {
+	key         K
+	value       V
 	numRequests int
+	lastRequest int // value of the cache's clock when the node was last requested
 	index       int // index of the node in the heap
 }
 
-type lfu struct {
-	hash map[int]*lfuNode
-	heap []*lfuNode
-	size int
+// lfu implements Policy and iCache interfaces
+// LFU evicts the key with the fewest requests. When multiple keys have
+// the same number of requests, the least recently used of them is evicted.
+type lfu[K comparable, V any] struct {
+	evictNotifier[K, V]
+	hash  map[K]*lfuNode[K, V]
+	heap  []*lfuNode[K, V]
+	size  int
+	clock int // logical clock, ticks on every request
 }
 
-func newLFU(size int) *lfu {
-	return &lfu{
-		hash: make(map[int]*lfuNode),
-		heap: []*lfuNode{},
+func newLFU[K comparable, V any](size int) *lfu[K, V] {
+	return &lfu[K, V]{
+		hash: make(map[K]*lfuNode[K, V]),
+		heap: []*lfuNode[K, V]{},
 		size: size,
 	}
 }
 
-// The Cache interface
+// The Policy interface
 
-func (c *lfu) Read(key int) (value int, isCacheMiss bool) {
+func (c *lfu[K, V]) Read(key K) (value V, isCacheMiss bool) {
 	node := c.read(key)
 	if node == nil {
-		return 0, true
+		return value, true
 	}
 	return node.value, false
 }
 
-func (c *lfu) Write(key, value int) {
-	_, _ = c.write(key, value)
+func (c *lfu[K, V]) Write(key K, value V) {
+	if _, evicted := c.write(key, value); evicted != nil {
+		c.notifyEvict(evicted.key, evicted.value)
+	}
+}
+
+func (c *lfu[K, V]) Peek(key K) (value V, found bool) {
+	if node, present := c.hash[key]; present {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (c *lfu[K, V]) Delete(key K) bool {
+	return c.remove(key) != nil
+}
+
+func (c *lfu[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *lfu[K, V]) Capacity() int {
+	return c.size
+}
+
+func (c *lfu[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.heap))
+	for _, node := range c.heap {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
+func (c *lfu[K, V]) Purge() {
+	c.hash = make(map[K]*lfuNode[K, V])
+	c.heap = []*lfuNode[K, V]{}
+	c.clock = 0
+}
+
+func (c *lfu[K, V]) Resize(size int) {
+	for _, node := range c.resize(size) {
+		c.notifyEvict(node.key, node.value)
+	}
 }
 
 // The iCache interface
 
-func (c *lfu) read(key int) *lfuNode {
+func (c *lfu[K, V]) read(key K) *lfuNode[K, V] {
 	node, present := c.hash[key]
 	if !present {
 		return nil
@@ -46,35 +93,49 @@ func (c *lfu) read(key int) *lfuNode {
 	return node
 }
 
-func (c *lfu) write(key, value int) (node, evicted *lfuNode) {
+func (c *lfu[K, V]) write(key K, value V) (node, evicted *lfuNode[K, V]) {
 	if node, present := c.hash[key]; present {
 		node.value = value
 		c.increment(node)
 		return node, nil
 	}
-	node = &lfuNode{
+	c.clock++
+	node = &lfuNode[K, V]{
 		key:         key,
 		value:       value,
 		numRequests: 1,
+		lastRequest: c.clock,
 	}
-	if len(c.heap) >= c.size {
-		evicted = c.remove(c.heap[len(c.heap)-1].key)
+	if len(c.heap) >= c.size && len(c.heap) > 0 {
+		evicted = c.remove(c.heap[0].key)
 	}
 	c.hash[key] = node
 	c.heap = heapPush(c.heap, node)
 	return node, evicted
 }
 
-// increment will bump the numRequests property and promote the node in the heap.
+// resize changes the capacity and returns the nodes evicted to fit in,
+// in eviction order.
+func (c *lfu[K, V]) resize(size int) (evicted []*lfuNode[K, V]) {
+	c.size = size
+	for len(c.heap) > c.size {
+		evicted = append(evicted, c.remove(c.heap[0].key))
+	}
+	return evicted
+}
+
+// increment will bump the numRequests property and push the node down the heap.
 // increment assumes the node is still in the cache.
-func (c *lfu) increment(node *lfuNode) {
+func (c *lfu[K, V]) increment(node *lfuNode[K, V]) {
+	c.clock++
 	node.numRequests++
-	heapBubbleUp(c.heap, node.index)
+	node.lastRequest = c.clock
+	heapBubbleDown(c.heap, node.index)
 }
 
-// remove moves the node corresponding to key to the slot in the heap then
-// bubbles down the interchanged value and resizes the heap.
-func (c *lfu) remove(key int) *lfuNode {
+// remove moves the node corresponding to key to the last slot in the heap,
+// resizes the heap then restores the heap invariant for the interchanged node.
+func (c *lfu[K, V]) remove(key K) *lfuNode[K, V] {
 	node, exists := c.hash[key]
 	if !exists {
 		return nil
@@ -86,30 +147,35 @@ func (c *lfu) remove(key int) *lfuNode {
 	node.index = lastIndex
 
 	delete(c.hash, node.key)
+	c.heap[lastIndex] = nil
 	c.heap = c.heap[:lastIndex]
-	heapBubbleDown(c.heap, index)
+	if index < lastIndex {
+		heapBubbleUp(c.heap, index)
+		heapBubbleDown(c.heap, index)
+	}
 
 	return node
 }
 
-// Max heap data structure is modeled as a slice of *lfuNode and maintains the
-// node with the largest numRequests at the head of the array.
+// Min heap data structure is modeled as a slice of *lfuNode and maintains the
+// node with the smallest numRequests at the head of the array. Ties are
+// broken by lastRequest, so the head is always the next node to evict.
 
 // heapPush inserts a new node in the heap, preserving the heap invariant.
 // heapPush maintains the index property of each node
-func heapPush(heap []*lfuNode, node *lfuNode) []*lfuNode {
+func heapPush[K comparable, V any](heap []*lfuNode[K, V], node *lfuNode[K, V]) []*lfuNode[K, V] {
 	heap = append(heap, node)
 	node.index = len(heap) - 1
 	heapBubbleUp(heap, node.index)
 	return heap
 }
 
-func heapBubbleUp(heap []*lfuNode, index int) {
-	parentIndex := (index - 1) / 2
-	if parentIndex < 0 {
+func heapBubbleUp[K comparable, V any](heap []*lfuNode[K, V], index int) {
+	if index == 0 {
 		return
 	}
-	if heap[parentIndex].numRequests >= heap[index].numRequests {
+	parentIndex := (index - 1) / 2
+	if !heapLess(heap[index], heap[parentIndex]) {
 		return
 	}
 	heap[parentIndex], heap[index] = heap[index], heap[parentIndex]
@@ -118,28 +184,36 @@ func heapBubbleUp(heap []*lfuNode, index int) {
 	heapBubbleUp(heap, parentIndex)
 }
 
-func heapBubbleDown(heap []*lfuNode, parentIndex int) {
+func heapBubbleDown[K comparable, V any](heap []*lfuNode[K, V], parentIndex int) {
 	leftIndex, rightIndex := parentIndex*2+1, parentIndex*2+2
-	maxIndex := getMaxIndex(heap, parentIndex, leftIndex, rightIndex)
-	if maxIndex == parentIndex {
+	minIndex := getMinIndex(heap, parentIndex, leftIndex, rightIndex)
+	if minIndex == parentIndex {
 		return
 	}
-	heap[maxIndex], heap[parentIndex] = heap[parentIndex], heap[maxIndex]
+	heap[minIndex], heap[parentIndex] = heap[parentIndex], heap[minIndex]
 	heap[parentIndex].index = parentIndex
-	heap[maxIndex].index = maxIndex
+	heap[minIndex].index = minIndex
 
-	heapBubbleDown(heap, maxIndex)
+	heapBubbleDown(heap, minIndex)
 }
 
-func getMaxIndex(heap []*lfuNode, parent, left, right int) int {
-	maxIndex := parent
+func getMinIndex[K comparable, V any](heap []*lfuNode[K, V], parent, left, right int) int {
+	minIndex := parent
 	for _, i := range []int{left, right} {
 		if i >= len(heap) {
 			continue
 		}
-		if heap[maxIndex].value < heap[i].value {
-			maxIndex = i
+		if heapLess(heap[i], heap[minIndex]) {
+			minIndex = i
 		}
 	}
-	return maxIndex
+	return minIndex
+}
+
+// heapLess reports whether a should be evicted before b.
+func heapLess[K comparable, V any](a, b *lfuNode[K, V]) bool {
+	if a.numRequests != b.numRequests {
+		return a.numRequests < b.numRequests
+	}
+	return a.lastRequest < b.lastRequest
 }
@@ -0,0 +1,252 @@
+package cache
+
+// lfuList implements Policy and iCache interfaces
+// It's an LFU which runs all operations in constant time. Instead of a heap,
+// nodes are grouped in buckets by their number of requests, the buckets are
+// kept in a doubly-linked list sorted by number of requests and each bucket
+// holds its nodes in a doubly-linked list sorted by recency. This way the
+// next node to evict, the least recently used among the least frequently used,
+// is always the last node of the first bucket.
+type lfuList[K comparable, V any] struct {
+	evictNotifier[K, V]
+	size  int
+	first *lfuBucket[K, V] // bucket with the fewest requests
+	hash  map[K]*lfuListNode[K, V]
+}
+
+type lfuBucket[K comparable, V any] struct {
+	numRequests int
+	head        *lfuListNode[K, V] // most recently used node in the bucket
+	last        *lfuListNode[K, V] // least recently used node in the bucket
+	next        *lfuBucket[K, V]
+	previous    *lfuBucket[K, V]
+}
+
+type lfuListNode[K comparable, V any] struct {
+	key      K
+	value    V
+	bucket   *lfuBucket[K, V]
+	next     *lfuListNode[K, V]
+	previous *lfuListNode[K, V]
+}
+
+func newLFUList[K comparable, V any](size int) *lfuList[K, V] {
+	return &lfuList[K, V]{
+		size:  size,
+		first: nil,
+		hash:  make(map[K]*lfuListNode[K, V]),
+	}
+}
+
+// The Policy interface
+
+func (c *lfuList[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node := c.read(key)
+	if node == nil {
+		return value, true
+	}
+	return node.value, false
+}
+
+func (c *lfuList[K, V]) Write(key K, value V) {
+	if _, evicted := c.write(key, value); evicted != nil {
+		c.notifyEvict(evicted.key, evicted.value)
+	}
+}
+
+func (c *lfuList[K, V]) Peek(key K) (value V, found bool) {
+	if node, present := c.hash[key]; present {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (c *lfuList[K, V]) Delete(key K) bool {
+	return c.remove(key) != nil
+}
+
+func (c *lfuList[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *lfuList[K, V]) Capacity() int {
+	return c.size
+}
+
+func (c *lfuList[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.hash))
+	for bucket := c.first; bucket != nil; bucket = bucket.next {
+		for node := bucket.head; node != nil; node = node.next {
+			keys = append(keys, node.key)
+		}
+	}
+	return keys
+}
+
+func (c *lfuList[K, V]) Purge() {
+	c.first = nil
+	c.hash = make(map[K]*lfuListNode[K, V])
+}
+
+func (c *lfuList[K, V]) Resize(size int) {
+	for _, node := range c.resize(size) {
+		c.notifyEvict(node.key, node.value)
+	}
+}
+
+// The iCache interface
+
+func (c *lfuList[K, V]) read(key K) *lfuListNode[K, V] {
+	node, present := c.hash[key]
+	if !present {
+		return nil
+	}
+	c.increment(node)
+	return node
+}
+
+func (c *lfuList[K, V]) write(key K, value V) (node, evicted *lfuListNode[K, V]) {
+	if node, present := c.hash[key]; present {
+		node.value = value
+		c.increment(node)
+		return node, nil
+	}
+	if len(c.hash) >= c.size && c.first != nil {
+		evicted = c.remove(c.first.last.key)
+	}
+	if c.first == nil || c.first.numRequests != 1 {
+		c.first = c.insertBucketAfter(nil, 1)
+	}
+	node = &lfuListNode[K, V]{
+		key:   key,
+		value: value,
+	}
+	c.hash[key] = node
+	c.first.push(node)
+	return node, evicted
+}
+
+func (c *lfuList[K, V]) remove(key K) *lfuListNode[K, V] {
+	node, present := c.hash[key]
+	if !present {
+		return nil
+	}
+	bucket := node.bucket
+	bucket.detach(node)
+	if bucket.head == nil {
+		c.removeBucket(bucket)
+	}
+	delete(c.hash, key)
+	return node
+}
+
+// resize changes the capacity and returns the nodes evicted to fit in,
+// in eviction order.
+func (c *lfuList[K, V]) resize(size int) (evicted []*lfuListNode[K, V]) {
+	c.size = size
+	for len(c.hash) > c.size {
+		evicted = append(evicted, c.remove(c.first.last.key))
+	}
+	return evicted
+}
+
+// Helpers
+
+// increment moves the node to the bucket for one more request, creating
+// that bucket if needed and dropping the old one if it's left empty.
+func (c *lfuList[K, V]) increment(node *lfuListNode[K, V]) {
+	current := node.bucket
+	next := current.next
+	if next == nil || next.numRequests != current.numRequests+1 {
+		next = c.insertBucketAfter(current, current.numRequests+1)
+	}
+	current.detach(node)
+	if current.head == nil {
+		c.removeBucket(current)
+	}
+	next.push(node)
+}
+
+// insertBucketAfter links a new empty bucket after the given one.
+// If previous is nil, the new bucket becomes the first one.
+func (c *lfuList[K, V]) insertBucketAfter(previous *lfuBucket[K, V], numRequests int) *lfuBucket[K, V] {
+	bucket := &lfuBucket[K, V]{
+		numRequests: numRequests,
+		previous:    previous,
+	}
+	if previous == nil {
+		bucket.next = c.first
+		c.first = bucket
+	} else {
+		bucket.next = previous.next
+		previous.next = bucket
+	}
+	if bucket.next != nil {
+		bucket.next.previous = bucket
+	}
+	return bucket
+}
+
+func (c *lfuList[K, V]) removeBucket(bucket *lfuBucket[K, V]) {
+	if bucket.previous == nil {
+		c.first = bucket.next
+	} else {
+		bucket.previous.next = bucket.next
+	}
+	if bucket.next != nil {
+		bucket.next.previous = bucket.previous
+	}
+	bucket.next = nil
+	bucket.previous = nil
+}
+
+// push makes the node the head of the bucket, ie. its most recently used node.
+func (b *lfuBucket[K, V]) push(node *lfuListNode[K, V]) {
+	node.bucket = b
+	node.previous = nil
+	node.next = b.head
+	if b.head == nil {
+		b.last = node
+	} else {
+		b.head.previous = node
+	}
+	b.head = node
+}
+
+// detach unlinks the node from the bucket.
+func (b *lfuBucket[K, V]) detach(node *lfuListNode[K, V]) {
+	if node.previous == nil {
+		b.head = node.next
+	} else {
+		node.previous.next = node.next
+	}
+	if node.next == nil {
+		b.last = node.previous
+	} else {
+		node.next.previous = node.previous
+	}
+	node.next = nil
+	node.previous = nil
+	node.bucket = nil
+}
+
+type lfuListState[K comparable] struct {
+	size    int
+	buckets map[int][]K // keys in each bucket, from most to least recently used
+	order   []int       // number of requests of each bucket, in list order
+}
+
+func (c *lfuList[K, V]) state() lfuListState[K] {
+	output := lfuListState[K]{
+		size:    c.size,
+		buckets: make(map[int][]K),
+		order:   []int{},
+	}
+	for bucket := c.first; bucket != nil; bucket = bucket.next {
+		output.order = append(output.order, bucket.numRequests)
+		for node := bucket.head; node != nil; node = node.next {
+			output.buckets[bucket.numRequests] = append(output.buckets[bucket.numRequests], node.key)
+		}
+	}
+	return output
+}
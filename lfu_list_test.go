@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestLFUList(t *testing.T) {
+	t.Run("nodes move between buckets as they are requested", func(t *testing.T) {
+		c := newLFUList[int, int](3)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		_, _ = c.Read(1)
+		_, _ = c.Read(2)
+		state := c.state()
+		if !reflect.DeepEqual(state.order, []int{1, 2, 3}) {
+			t.Fatalf("unexpected order of buckets: %#v", state.order)
+		}
+		if !reflect.DeepEqual(state.buckets, map[int][]int{1: {3}, 2: {2}, 3: {1}}) {
+			t.Fatalf("unexpected content of buckets: %#v", state.buckets)
+		}
+		_, _ = c.Read(3)
+		_, _ = c.Read(2)
+		state = c.state()
+		if !reflect.DeepEqual(state.order, []int{2, 3}) {
+			t.Fatalf("expected empty buckets to be dropped but got: %#v", state.order)
+		}
+		if !reflect.DeepEqual(state.buckets, map[int][]int{2: {3}, 3: {2, 1}}) {
+			t.Fatalf("unexpected content of buckets: %#v", state.buckets)
+		}
+	})
+	t.Run("least recently used key is evicted among the least frequently used", func(t *testing.T) {
+		c := newLFUList[int, int](3)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		_, evicted := c.write(4, 40)
+		if evicted == nil || evicted.key != 2 {
+			t.Fatalf("expected key 2 to be evicted but got %#v", evicted)
+		}
+		_, _ = c.Read(4)
+		_, evicted = c.write(5, 50)
+		if evicted == nil || evicted.key != 3 {
+			t.Fatalf("expected key 3 to be evicted but got %#v", evicted)
+		}
+		if value, isCacheMiss := c.Read(5); isCacheMiss || value != 50 {
+			t.Fatalf("expected to read key 5 but got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+	})
+	t.Run("behaves exactly like the heap based LFU", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(7))
+		for _, size := range []int{1, 2, 5, 16} {
+			list, heap := newLFUList[int, int](size), newLFU[int, int](size)
+			for step := 0; step < 5000; step++ {
+				key := rnd.Intn(3 * size)
+				switch op := rnd.Intn(10); {
+				case op < 5:
+					listValue, listMiss := list.Read(key)
+					heapValue, heapMiss := heap.Read(key)
+					if listValue != heapValue || listMiss != heapMiss {
+						t.Fatalf("size %d, step %d: read %d returned (%d, %t) instead of (%d, %t)",
+							size, step, key, listValue, listMiss, heapValue, heapMiss)
+					}
+				case op < 9:
+					list.Write(key, step)
+					heap.Write(key, step)
+				default:
+					if list.Delete(key) != heap.Delete(key) {
+						t.Fatalf("size %d, step %d: delete %d does not match", size, step, key)
+					}
+				}
+				checkLFUList(t, list)
+				if list.Len() != heap.Len() {
+					t.Fatalf("size %d, step %d: list has %d keys and heap has %d", size, step, list.Len(), heap.Len())
+				}
+			}
+		}
+	})
+}
+
+func checkLFUList(t *testing.T, c *lfuList[int, int]) {
+	t.Helper()
+	numNodes := 0
+	for bucket := c.first; bucket != nil; bucket = bucket.next {
+		if bucket.head == nil {
+			t.Fatalf("bucket %d is empty", bucket.numRequests)
+		}
+		if bucket.next != nil && (bucket.next.numRequests <= bucket.numRequests || bucket.next.previous != bucket) {
+			t.Fatalf("bucket %d is not linked correctly to the next one", bucket.numRequests)
+		}
+		for node := bucket.head; node != nil; node = node.next {
+			if node.bucket != bucket || c.hash[node.key] != node {
+				t.Fatalf("node %#v is not linked correctly", node)
+			}
+			if node.next == nil && bucket.last != node {
+				t.Fatalf("bucket %d does not point to its last node", bucket.numRequests)
+			}
+			numNodes++
+		}
+	}
+	if numNodes != len(c.hash) || numNodes > c.size {
+		t.Fatalf("buckets hold %d nodes and hash has %d for a cache of size %d", numNodes, len(c.hash), c.size)
+	}
+}
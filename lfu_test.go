@@ -1,12 +1,13 @@
 package cache
 
 import (
+	"math/rand"
 	"testing"
 )
 
 func TestLFU(t *testing.T) {
 	t.Run("cache state for base cases is correct", func(t *testing.T) {
-		c := newLFU(2)
+		c := newLFU[int, int](2)
 		if len(c.heap) != 0 || len(c.hash) != 0 || c.size != 2 {
 			t.Fatalf("empty cache state is incorrect: %#v", c)
 		}
@@ -22,7 +23,7 @@ func TestLFU(t *testing.T) {
 		}
 	})
 	t.Run("cache state after eviction is correct", func(t *testing.T) {
-		c := newLFU(2)
+		c := newLFU[int, int](2)
 		c.Write(1, 10)
 		if len(c.heap) != 1 || len(c.hash) != 1 || c.heap[0].key != 1 ||
 			c.heap[0].value != 10 || c.heap[0].numRequests != 1 || c.heap[0].index != 0 {
@@ -33,16 +34,16 @@ func TestLFU(t *testing.T) {
 			c.heap[1].value != 20 || c.heap[1].numRequests != 1 || c.heap[1].index != 1 {
 			t.Fatalf("cache with two values has incorrect state: %#v", c.heap)
 		}
-		actualValue, isCacheMiss := c.Read(1) // {1, 2}
+		actualValue, isCacheMiss := c.Read(1) // {2, 1}
 		if actualValue != 10 || isCacheMiss == true || len(c.heap) != 2 ||
-			len(c.hash) != 2 || c.heap[0].key != 1 || c.heap[0].value != 10 ||
-			c.heap[0].numRequests != 2 || c.heap[0].index != 0 {
-			t.Fatalf("cache after a read has incorrect state: %#v", c.heap[0])
-		}
-		c.Write(3, 30) // {1, 3}
-		if len(c.heap) != 2 || len(c.hash) != 2 || c.heap[0].key != 1 || c.heap[0].value != 10 ||
-			c.heap[1].key != 3 || c.heap[1].value != 30 || c.heap[1].numRequests != 1 ||
-			c.heap[1].index != 1 {
+			len(c.hash) != 2 || c.heap[1].key != 1 || c.heap[1].value != 10 ||
+			c.heap[1].numRequests != 2 || c.heap[1].index != 1 {
+			t.Fatalf("cache after a read has incorrect state: %#v", c.heap[1])
+		}
+		c.Write(3, 30) // {3, 1}
+		if len(c.heap) != 2 || len(c.hash) != 2 || c.heap[1].key != 1 || c.heap[1].value != 10 ||
+			c.heap[0].key != 3 || c.heap[0].value != 30 || c.heap[0].numRequests != 1 ||
+			c.heap[0].index != 0 {
 			t.Fatalf("cache after another write has incorrect state: #0:%#v #1:%#v", c.heap[0], c.heap[1])
 		}
 		actualValue, isCacheMiss = c.Read(2)
@@ -51,7 +52,7 @@ func TestLFU(t *testing.T) {
 		}
 	})
 	t.Run("check cache state after multiple reads and writes", func(t *testing.T) {
-		c := newLFU(2)
+		c := newLFU[int, int](2)
 		c.Write(2, 20)
 		c.Write(1, 10)
 		node := c.remove(2)
@@ -62,4 +63,73 @@ func TestLFU(t *testing.T) {
 			t.Fatalf("cache is in an inconsistent state %#v", c.heap)
 		}
 	})
+	t.Run("least recently used key is evicted among the least frequently used", func(t *testing.T) {
+		c := newLFU[int, int](3)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		c.Write(4, 40) // 2 and 3 were requested once, 2 is older.
+		if _, isCacheMiss := c.Read(2); !isCacheMiss {
+			t.Fatalf("expected key 2 to be evicted: %#v", c.hash)
+		}
+		_, _ = c.Read(4)
+		c.Write(5, 50) // 3 is the only key requested once.
+		if _, isCacheMiss := c.Read(3); !isCacheMiss {
+			t.Fatalf("expected key 3 to be evicted: %#v", c.hash)
+		}
+	})
+	t.Run("heap invariant holds after every operation", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(42))
+		for _, size := range []int{1, 2, 3, 7, 16} {
+			c := newLFU[int, int](size)
+			for step := 0; step < 5000; step++ {
+				key := rnd.Intn(3 * size)
+				switch op := rnd.Intn(10); {
+				case op < 5:
+					_, _ = c.Read(key)
+				case op < 9:
+					_, exists := c.hash[key]
+					isFull := len(c.hash) == size
+					victim := c.minNode()
+					_, evicted := c.write(key, key)
+					if !exists && isFull && evicted != victim {
+						t.Fatalf("size %d, step %d: evicted %#v instead of %#v", size, step, evicted, victim)
+					}
+				default:
+					_ = c.Delete(key)
+				}
+				checkLFUHeap(t, c)
+			}
+		}
+	})
+}
+
+// minNode finds the node with the fewest requests by scanning the whole heap.
+func (c *lfu[K, V]) minNode() *lfuNode[K, V] {
+	var victim *lfuNode[K, V]
+	for _, node := range c.heap {
+		if victim == nil || heapLess(node, victim) {
+			victim = node
+		}
+	}
+	return victim
+}
+
+func checkLFUHeap(t *testing.T, c *lfu[int, int]) {
+	t.Helper()
+	if len(c.heap) != len(c.hash) || len(c.heap) > c.size {
+		t.Fatalf("heap has %d nodes and hash has %d for a cache of size %d", len(c.heap), len(c.hash), c.size)
+	}
+	for i, node := range c.heap {
+		if node.index != i {
+			t.Fatalf("node %#v is stored at index %d", node, i)
+		}
+		if c.hash[node.key] != node {
+			t.Fatalf("node %#v is not referenced by the hash", node)
+		}
+		if parent := (i - 1) / 2; i > 0 && heapLess(node, c.heap[parent]) {
+			t.Fatalf("heap invariant broken between %#v and its parent %#v", node, c.heap[parent])
+		}
+	}
 }
@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"math"
+)
+
+// lirsHIRRatio is the share of the capacity given to resident HIR keys, as recommended by the paper.
+const lirsHIRRatio = 0.01
+
+// lirs implements Policy
+// It follows the LIRS algorithm as described in "LIRS: An Efficient Low
+// Inter-reference Recency Set Replacement Policy to Improve Buffer Cache
+// Performance" by Song Jiang and Xiaodong Zhang.
+// Keys with a low inter-reference recency (LIR) take most of the cache, the
+// few resident high inter-reference recency (HIR) keys are evicted first.
+type lirs[K comparable, V any] struct {
+	evictNotifier[K, V]
+	stack *lru[K, *lirsEntry[V]] // the head is the top of the stack
+	queue *lru[K, *lirsEntry[V]] // the last key is the next to evict
+	// nonResident tracks the non-resident HIR keys in the stack, to drop the
+	// oldest ones when there are more than c of them.
+	nonResident *lru[K, struct{}]
+	c           int
+	lirSize     int // maximum number of LIR keys
+	numLIR      int
+}
+
+type lirsEntry[V any] struct {
+	value    V
+	lir      bool
+	resident bool
+}
+
+func newLIRS[K comparable, V any](size int) *lirs[K, V] {
+	c := &lirs[K, V]{
+		stack:       newLRU[K, *lirsEntry[V]](math.MaxInt),
+		queue:       newLRU[K, *lirsEntry[V]](math.MaxInt),
+		nonResident: newLRU[K, struct{}](math.MaxInt),
+	}
+	c.setSize(size)
+	return c
+}
+
+func (c *lirs[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	e := c.find(key)
+	if e == nil || !e.resident {
+		return value, true
+	}
+	c.hit(key, e)
+	return e.value, false
+}
+
+func (c *lirs[K, V]) Write(key K, value V) {
+	e := c.find(key)
+	if e != nil && e.resident {
+		e.value = value
+		c.hit(key, e)
+		return
+	}
+	if c.Len() >= c.c {
+		c.evict()
+	}
+	if e == nil {
+		e = &lirsEntry[V]{}
+	}
+	e.value = value
+	e.resident = true
+	c.miss(key, e)
+	c.trimNonResident()
+}
+
+func (c *lirs[K, V]) Peek(key K) (value V, found bool) {
+	if e := c.find(key); e != nil && e.resident {
+		return e.value, true
+	}
+	return value, false
+}
+
+func (c *lirs[K, V]) Delete(key K) bool {
+	e := c.find(key)
+	if e == nil {
+		return false
+	}
+	_ = c.stack.remove(key)
+	_ = c.queue.remove(key)
+	_ = c.nonResident.remove(key)
+	if e.lir {
+		c.numLIR--
+	}
+	c.prune()
+	return e.resident
+}
+
+func (c *lirs[K, V]) Len() int {
+	return c.numLIR + c.queue.Len()
+}
+
+func (c *lirs[K, V]) Capacity() int {
+	return c.c
+}
+
+// Keys returns the LIR keys from the top of the stack, then the resident HIR keys.
+func (c *lirs[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	for node := c.stack.head; node != nil; node = node.next {
+		if node.value.lir {
+			keys = append(keys, node.key)
+		}
+	}
+	return append(keys, c.queue.Keys()...)
+}
+
+func (c *lirs[K, V]) Purge() {
+	c.stack.Purge()
+	c.queue.Purge()
+	c.nonResident.Purge()
+	c.numLIR = 0
+}
+
+// Resize scales the number of LIR keys to the new size. When shrinking, the
+// bottom LIR keys become HIR keys until there are few enough of them, then
+// resident HIR keys are evicted until the cache fits.
+func (c *lirs[K, V]) Resize(size int) {
+	c.setSize(size)
+	for c.numLIR > c.lirSize {
+		c.demote()
+	}
+	for c.Len() > c.c {
+		c.evict()
+	}
+	c.trimNonResident()
+}
+
+// hit handles a request for a resident key.
+func (c *lirs[K, V]) hit(key K, e *lirsEntry[V]) {
+	switch {
+	case e.lir:
+		_ = c.stack.read(key)
+		c.prune()
+	case c.stack.read(key) != nil:
+		// The HIR key was requested again while it was in the stack.
+		_ = c.queue.remove(key)
+		c.promote(e)
+	default:
+		_ = c.stack.insert(key, e)
+		_ = c.queue.read(key)
+		// Without LIR keys, ie. for a cache of size 1, HIR keys don't stay in the stack.
+		c.prune()
+	}
+}
+
+// miss handles a request for a key which is not resident, but might still be in the stack.
+func (c *lirs[K, V]) miss(key K, e *lirsEntry[V]) {
+	inStack := c.stack.read(key) != nil
+	if inStack {
+		_ = c.nonResident.remove(key)
+	} else {
+		_ = c.stack.insert(key, e)
+	}
+	switch {
+	case c.numLIR < c.lirSize:
+		// While there is room for LIR keys, all the keys are LIR.
+		e.lir = true
+		c.numLIR++
+	case inStack:
+		c.promote(e)
+	default:
+		_ = c.queue.insert(key, e)
+		c.prune()
+	}
+}
+
+// promote turns an HIR key at the top of the stack into a LIR key
+// and the bottom LIR key into a resident HIR key.
+func (c *lirs[K, V]) promote(e *lirsEntry[V]) {
+	e.lir = true
+	c.numLIR++
+	c.demote()
+}
+
+// demote turns the bottom LIR key of the stack into a resident HIR key.
+func (c *lirs[K, V]) demote() {
+	node := c.stack.remove(c.stack.last.key)
+	node.value.lir = false
+	c.numLIR--
+	_ = c.queue.insert(node.key, node.value)
+	c.prune()
+}
+
+// evict drops the resident HIR key at the front of the queue. If it's
+// still in the stack, it stays there as a non-resident HIR key.
+func (c *lirs[K, V]) evict() {
+	if c.queue.Len() == 0 {
+		c.demote()
+	}
+	node := c.queue.remove(c.queue.last.key)
+	node.value.resident = false
+	if _, inStack := c.stack.hash[node.key]; inStack {
+		_ = c.nonResident.insert(node.key, struct{}{})
+	}
+	c.notifyEvict(node.key, node.value.value)
+	var zero V
+	node.value.value = zero
+}
+
+// prune removes the HIR keys from the bottom of the stack, so that its
+// bottom is a LIR key. Non-resident keys are forgotten altogether.
+func (c *lirs[K, V]) prune() {
+	for c.stack.last != nil && !c.stack.last.value.lir {
+		node := c.stack.remove(c.stack.last.key)
+		if !node.value.resident {
+			_ = c.nonResident.remove(node.key)
+		}
+	}
+}
+
+// trimNonResident forgets the oldest non-resident HIR keys
+// so that the stack doesn't grow beyond twice the capacity.
+func (c *lirs[K, V]) trimNonResident() {
+	for c.nonResident.Len() > c.c {
+		node := c.nonResident.remove(c.nonResident.last.key)
+		_ = c.stack.remove(node.key)
+	}
+}
+
+// find returns the entry of a key in the stack or the queue, or nil.
+func (c *lirs[K, V]) find(key K) *lirsEntry[V] {
+	if node, present := c.stack.hash[key]; present {
+		return node.value
+	}
+	if node, present := c.queue.hash[key]; present {
+		return node.value
+	}
+	return nil
+}
+
+// setSize changes the capacity and splits it between LIR and resident HIR keys.
+func (c *lirs[K, V]) setSize(size int) {
+	c.c = size
+	c.lirSize = size - max(1, int(math.Round(float64(size)*lirsHIRRatio)))
+}
+
+type lirsState[K comparable] struct {
+	stack       []K // from the top of the stack
+	lir         []K // LIR keys, from the top of the stack
+	queue       []K // from the last key to enter the queue
+	nonResident []K
+}
+
+func (c *lirs[K, V]) state() lirsState[K] {
+	s := lirsState[K]{
+		queue:       c.queue.Keys(),
+		nonResident: []K{},
+	}
+	for node := c.stack.head; node != nil; node = node.next {
+		s.stack = append(s.stack, node.key)
+		if node.value.lir {
+			s.lir = append(s.lir, node.key)
+		}
+		if !node.value.resident {
+			s.nonResident = append(s.nonResident, node.key)
+		}
+	}
+	return s
+}
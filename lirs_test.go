@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLIRS(t *testing.T) {
+	t.Run("hir keys requested again while in the stack become lir keys", func(t *testing.T) {
+		type step struct {
+			write    bool
+			key      int
+			hit      bool // only checked for reads
+			expected lirsState[int]
+			evicted  []int // keys evicted by the step
+		}
+		steps := []step{ // size=3, 2 lir keys and 1 resident hir key
+			{write: true, key: 1, expected: lirsState[int]{stack: []int{1}, lir: []int{1}}},
+			{write: true, key: 2, expected: lirsState[int]{stack: []int{2, 1}, lir: []int{2, 1}}},
+			{write: true, key: 3, expected: lirsState[int]{stack: []int{3, 2, 1}, lir: []int{2, 1}, queue: []int{3}}},
+			// 3 stays in the stack after its eviction.
+			{write: true, key: 4, evicted: []int{3},
+				expected: lirsState[int]{stack: []int{4, 3, 2, 1}, lir: []int{2, 1}, queue: []int{4}, nonResident: []int{3}}},
+			{key: 1, hit: true,
+				expected: lirsState[int]{stack: []int{1, 4, 3, 2}, lir: []int{1, 2}, queue: []int{4}, nonResident: []int{3}}},
+			{key: 3,
+				expected: lirsState[int]{stack: []int{1, 4, 3, 2}, lir: []int{1, 2}, queue: []int{4}, nonResident: []int{3}}},
+			// 3 was requested more recently than 2, they switch status and the stack is pruned.
+			{write: true, key: 3, evicted: []int{4},
+				expected: lirsState[int]{stack: []int{3, 1}, lir: []int{3, 1}, queue: []int{2}}},
+			// 2 is not in the stack anymore, so it needs two requests to become a lir key.
+			{key: 2, hit: true,
+				expected: lirsState[int]{stack: []int{2, 3, 1}, lir: []int{3, 1}, queue: []int{2}}},
+			{key: 2, hit: true,
+				expected: lirsState[int]{stack: []int{2, 3}, lir: []int{2, 3}, queue: []int{1}}},
+		}
+		c := newLIRS[int, int](3)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i, step := range steps {
+			evicted = evicted[:0]
+			if step.write {
+				c.Write(step.key, step.key*10)
+			} else if value, isCacheMiss := c.Read(step.key); isCacheMiss == step.hit || (step.hit && value != step.key*10) {
+				t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+			}
+			if state := c.state(); !equalStates(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("deleted keys are forgotten by the stack", func(t *testing.T) {
+		c := newLIRS[int, int](3)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		c.Write(4, 40) // 3 is non-resident
+		if c.Delete(3) || !c.Delete(1) || c.Delete(1) {
+			t.Fatalf("unexpected deletes, state %#v", c.state())
+		}
+		expected := lirsState[int]{stack: []int{4, 2}, lir: []int{2}, queue: []int{4}}
+		if state := c.state(); !equalStates(state, expected) || c.Len() != 2 {
+			t.Fatalf("expected %#v after deletes but got %#v", expected, state)
+		}
+	})
+	t.Run("a cache of size one only holds a resident hir key", func(t *testing.T) {
+		c := newLIRS[int, int](1)
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		c.Write(2, 20)
+		expected := lirsState[int]{queue: []int{2}}
+		if state := c.state(); !equalStates(state, expected) || c.Len() != 1 {
+			t.Fatalf("expected %#v but got %#v", expected, state)
+		}
+	})
+	t.Run("shrinking demotes the bottom lir keys, then evicts hir keys", func(t *testing.T) {
+		c := newLIRS[int, int](100) // 99 lir keys
+		evicted := 0
+		c.SetEvictHandler(func(key, value int) {
+			evicted++
+		})
+		for key := 0; key < 100; key++ {
+			c.Write(key, key)
+		}
+		c.Resize(10) // 9 lir keys
+		state := c.state()
+		if c.Len() != 10 || evicted != 90 || !reflect.DeepEqual(state.lir, []int{98, 97, 96, 95, 94, 93, 92, 91, 90}) {
+			t.Fatalf("unexpected state %#v after shrinking", state)
+		}
+		// 99 was the first resident hir key, so it was the first to go.
+		if !reflect.DeepEqual(state.queue, []int{89}) {
+			t.Fatalf("expected the last demoted key to be the only resident hir key but got %#v", state.queue)
+		}
+	})
+	t.Run("hit rate beats lru and arc on a loop larger than the cache", func(t *testing.T) {
+		expectHigherHitRate(t, loopTrace(120, 20), 100, LIRS, LRU, ARC)
+	})
+}
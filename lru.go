@@ -1,47 +1,90 @@
 package cache
 
-func newLRU(size int) *lru {
-	return &lru{
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{
 		size: size,
 		head: nil,
 		last: nil,
-		hash: make(map[int]*lruNode),
+		hash: make(map[K]*lruNode[K, V]),
 	}
 }
 
-// lru implements Cache and iCache interfaces
+// lru implements Policy and iCache interfaces
 // LRU evicts the least-recently used key.
-type lru struct {
+type lru[K comparable, V any] struct {
+	evictNotifier[K, V]
 	size int
-	head *lruNode
-	last *lruNode
-	hash map[int]*lruNode
+	head *lruNode[K, V]
+	last *lruNode[K, V]
+	hash map[K]*lruNode[K, V]
 }
 
-type lruNode struct {
-	key      int
-	value    int
-	next     *lruNode
-	previous *lruNode
+type lruNode[K comparable, V any] struct {
+	key      K
+	value    V
+	next     *lruNode[K, V]
+	previous *lruNode[K, V]
 }
 
-// Cache interface
+// Policy interface
 
-func (c *lru) Read(key int) (value int, isCacheMiss bool) {
+func (c *lru[K, V]) Read(key K) (value V, isCacheMiss bool) {
 	node := c.read(key)
 	if node == nil {
-		return 0, true
+		return value, true
 	}
 	return node.value, false
 }
 
-func (c *lru) Write(key, value int) {
-	_, _ = c.write(key, value)
+func (c *lru[K, V]) Write(key K, value V) {
+	if _, evicted := c.write(key, value); evicted != nil {
+		c.notifyEvict(evicted.key, evicted.value)
+	}
+}
+
+func (c *lru[K, V]) Peek(key K) (value V, found bool) {
+	if node, exists := c.hash[key]; exists {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (c *lru[K, V]) Delete(key K) bool {
+	return c.remove(key) != nil
+}
+
+func (c *lru[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *lru[K, V]) Capacity() int {
+	return c.size
+}
+
+// Keys returns the keys from the most to the least recently used.
+func (c *lru[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.hash))
+	for node := c.head; node != nil; node = node.next {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
+func (c *lru[K, V]) Purge() {
+	c.head = nil
+	c.last = nil
+	c.hash = make(map[K]*lruNode[K, V])
+}
+
+func (c *lru[K, V]) Resize(size int) {
+	for _, node := range c.resize(size) {
+		c.notifyEvict(node.key, node.value)
+	}
 }
 
 // iCache interface
 
-func (c *lru) read(key int) *lruNode {
+func (c *lru[K, V]) read(key K) *lruNode[K, V] {
 	if node, exists := c.hash[key]; exists {
 		c.promote(key)
 		return node
@@ -49,7 +92,7 @@ func (c *lru) read(key int) *lruNode {
 	return nil
 }
 
-func (c *lru) write(key, value int) (node, evicted *lruNode) {
+func (c *lru[K, V]) write(key K, value V) (node, evicted *lruNode[K, V]) {
 	if node, exists := c.hash[key]; exists {
 		node.value = value
 		c.promote(key)
@@ -62,7 +105,7 @@ func (c *lru) write(key, value int) (node, evicted *lruNode) {
 	return node, evicted
 }
 
-func (c *lru) remove(key int) *lruNode {
+func (c *lru[K, V]) remove(key K) *lruNode[K, V] {
 	node, found := c.hash[key]
 	if !found {
 		return nil
@@ -96,11 +139,21 @@ func (c *lru) remove(key int) *lruNode {
 	return node
 }
 
+// resize changes the capacity and returns the nodes evicted to fit in,
+// from the least recently used one.
+func (c *lru[K, V]) resize(size int) (evicted []*lruNode[K, V]) {
+	c.size = size
+	for c.isOverflowing() {
+		evicted = append(evicted, c.remove(c.last.key))
+	}
+	return evicted
+}
+
 // Helpers
 
 // insert assumes node does not yet exist in the hash table.
-func (c *lru) insert(key, value int) *lruNode {
-	newNode := &lruNode{
+func (c *lru[K, V]) insert(key K, value V) *lruNode[K, V] {
+	newNode := &lruNode[K, V]{
 		key:      key,
 		value:    value,
 		previous: nil,
@@ -118,7 +171,7 @@ func (c *lru) insert(key, value int) *lruNode {
 	return newNode
 }
 
-func (c *lru) promote(key int) {
+func (c *lru[K, V]) promote(key K) {
 	node, exists := c.hash[key]
 	if !exists {
 		return
@@ -148,24 +201,29 @@ func (c *lru) promote(key int) {
 	c.head = node
 }
 
-func (c *lru) isOverflowing() bool {
+func (c *lru[K, V]) isOverflowing() bool {
 	return len(c.hash) > c.size
 }
 
-type lruState struct {
+type lruState[K comparable, V any] struct {
 	size int
-	list [][]int
-	hash map[int]int
+	list []lruEntry[K, V]
+	hash map[K]V
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
 }
 
-func (c *lru) state() lruState {
-	output := lruState{
+func (c *lru[K, V]) state() lruState[K, V] {
+	output := lruState[K, V]{
 		size: c.size,
-		list: [][]int{},
-		hash: make(map[int]int),
+		list: []lruEntry[K, V]{},
+		hash: make(map[K]V),
 	}
 	for node := c.head; node != nil; node = node.next {
-		output.list = append(output.list, []int{node.key, node.value})
+		output.list = append(output.list, lruEntry[K, V]{node.key, node.value})
 	}
 	for key, node := range c.hash {
 		output.hash[key] = node.value
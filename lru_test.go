@@ -7,7 +7,7 @@ import (
 
 func TestLRU(t *testing.T) {
 	t.Run("empty cache has correct state", func(t *testing.T) {
-		c := newLRU(2)
+		c := newLRU[int, int](2)
 		if c.size != 2 {
 			t.Errorf("expected size of new cache to be %d but is %d", 2, c.size)
 		}
@@ -26,7 +26,7 @@ func TestLRU(t *testing.T) {
 		}
 	})
 	t.Run("cache with one value has correct state", func(t *testing.T) {
-		c := newLRU(2)
+		c := newLRU[int, int](2)
 		c.Write(10, 100)
 		if c.head == nil || c.head.key != 10 && c.head.value != 100 {
 			t.Errorf("expected head of cache to be (10, 100) but is %#v", c.head)
@@ -39,7 +39,7 @@ func TestLRU(t *testing.T) {
 		}
 	})
 	t.Run("cache with two values has correct state", func(t *testing.T) {
-		c := newLRU(2)
+		c := newLRU[int, int](2)
 		c.Write(10, 100)
 		c.Write(20, 200)
 		if c.head == nil || c.head.key != 20 && c.head.value != 200 {
@@ -56,7 +56,7 @@ func TestLRU(t *testing.T) {
 		}
 	})
 	t.Run("cache correctly evicts least recently used value", func(t *testing.T) {
-		c := newLRU(2)
+		c := newLRU[int, int](2)
 		c.Write(10, 100)
 		c.Write(20, 200)
 		c.Write(30, 300)
@@ -74,7 +74,7 @@ func TestLRU(t *testing.T) {
 		}
 	})
 	t.Run(".remove() correctly delete a key in the middle of the linked list", func(t *testing.T) {
-		c := newLRU(3)
+		c := newLRU[int, int](3)
 		c.Write(10, 100)
 		c.Write(20, 200)
 		c.Write(30, 300)
@@ -83,7 +83,7 @@ func TestLRU(t *testing.T) {
 			t.Fatalf("expected the correct reeturn value from remove but got %#v", node)
 		}
 		state := c.state()
-		if !reflect.DeepEqual(state.list, [][]int{ {30, 300}, {10, 100} }) {
+		if !reflect.DeepEqual(state.list, []lruEntry[int, int]{ {30, 300}, {10, 100} }) {
 			t.Fatalf("unexpected cache linked list state: %#v", state.list)
 		}
 		if !reflect.DeepEqual(state.hash, map[int]int{ 30: 300, 10: 100 }) {
@@ -91,7 +91,7 @@ func TestLRU(t *testing.T) {
 		}
 	})
 	t.Run("head and last pointers are correct", func(t *testing.T) {
-		c := newLRU(3)
+		c := newLRU[int, int](3)
 		for idx, tc := range []struct{
 			op string
 			arg int
@@ -119,4 +119,27 @@ func TestLRU(t *testing.T) {
 		}
 
 	})
+	t.Run(".Resize() evicts the least recently used keys", func(t *testing.T) {
+		c := newLRU[int, int](4)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i := 1; i <= 4; i++ {
+			c.Write(i, i)
+		}
+		_, _ = c.Read(1)
+		c.Resize(2)
+		if state := c.state(); !reflect.DeepEqual(state.list, []lruEntry[int, int]{{1, 1}, {4, 4}}) {
+			t.Fatalf("unexpected cache state after shrinking: %#v", state.list)
+		}
+		if !reflect.DeepEqual(evicted, []int{2, 3}) {
+			t.Fatalf("unexpected evictions: %#v", evicted)
+		}
+		c.Resize(3)
+		c.Write(5, 5)
+		if c.Len() != 3 || c.Capacity() != 3 {
+			t.Fatalf("expected the cache to grow to 3 keys but got %#v", c.state())
+		}
+	})
 }
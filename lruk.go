@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"container/heap"
+	"math"
+)
+
+const (
+	// defaultK makes LRU-K behave like LRU-2, which the paper recommends.
+	defaultK = 2
+	// defaultCorrelatedPeriod considers every request as uncorrelated.
+	defaultCorrelatedPeriod = 0
+)
+
+// lruK implements Policy
+// It follows LRU-K as described in "The LRU-K Page Replacement Algorithm For
+// Database Disk Buffering" by Elizabeth J. O'Neil, Patrick E. O'Neil and
+// Gerhard Weikum.
+//
+// LRU-K remembers the times of the last K requests of each key and evicts
+// the key whose K-th most recent request is the oldest. Keys requested less
+// than K times are evicted first, the least recently used of them first, so
+// keys requested only once, eg. by a scan, don't push out the popular ones.
+// Time is counted in requests.
+//
+// Requests which follow the previous request of the same key within the
+// correlated reference period, eg. multiple reads of the same page in a
+// transaction, only count as one: they don't shift the history of the key
+// and the key can't be evicted until the period is over, unless all the keys
+// are in that case. The history of evicted keys is kept in a table as large
+// as the cache, so that keys requested again soon after their eviction
+// don't start over.
+type lruK[K comparable, V any] struct {
+	evictNotifier[K, V]
+	resident         *lru[K, *lruKEntry[K, V]] // from the most to the least recently requested
+	correlated       *lru[K, *lruKEntry[K, V]] // keys which may still be in their correlated reference period
+	heap             lruKHeap[K, V]            // keys out of their correlated reference period
+	history          *lru[K, *lruKHistory]     // keys which are not resident anymore
+	size             int
+	k                int
+	correlatedPeriod int
+	now              int
+}
+
+type lruKEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	history *lruKHistory
+	index   int // index of the entry in the heap, -1 if it's in correlated
+}
+
+// lruKHistory holds the request times of a key.
+type lruKHistory struct {
+	requests []int // times of the last K uncorrelated requests, most recent first, 0 if unknown
+	last     int   // time of the last request, correlated or not
+}
+
+// resident and correlated are never allowed to overflow on their own, lruK
+// decides which key to evict. history drops its oldest keys on its own.
+func newLRUK[K comparable, V any](size, k, correlatedPeriod int) *lruK[K, V] {
+	return &lruK[K, V]{
+		resident:         newLRU[K, *lruKEntry[K, V]](math.MaxInt),
+		correlated:       newLRU[K, *lruKEntry[K, V]](math.MaxInt),
+		history:          newLRU[K, *lruKHistory](size),
+		size:             size,
+		k:                k,
+		correlatedPeriod: correlatedPeriod,
+	}
+}
+
+func (c *lruK[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node := c.resident.read(key)
+	if node == nil {
+		return value, true
+	}
+	c.request(node.value)
+	return node.value.value, false
+}
+
+func (c *lruK[K, V]) Write(key K, value V) {
+	if node := c.resident.read(key); node != nil {
+		node.value.value = value
+		c.request(node.value)
+		return
+	}
+	e := &lruKEntry[K, V]{key: key, value: value, history: &lruKHistory{requests: make([]int, c.k)}, index: -1}
+	if node := c.history.remove(key); node != nil {
+		e.history = node.value
+	}
+	c.request(e)
+	if c.resident.Len() >= c.size {
+		c.evict()
+	}
+	_ = c.resident.insert(key, e)
+}
+
+func (c *lruK[K, V]) Peek(key K) (value V, found bool) {
+	if node, found := c.resident.hash[key]; found {
+		return node.value.value, true
+	}
+	return value, false
+}
+
+func (c *lruK[K, V]) Delete(key K) bool {
+	if c.history.Delete(key) {
+		return false
+	}
+	node := c.resident.remove(key)
+	if node == nil {
+		return false
+	}
+	c.unlink(node.value)
+	return true
+}
+
+func (c *lruK[K, V]) Len() int {
+	return c.resident.Len()
+}
+
+func (c *lruK[K, V]) Capacity() int {
+	return c.size
+}
+
+// Keys returns the keys from the most to the least recently requested.
+func (c *lruK[K, V]) Keys() []K {
+	return c.resident.Keys()
+}
+
+func (c *lruK[K, V]) Purge() {
+	c.resident.Purge()
+	c.correlated.Purge()
+	c.heap = nil
+	c.history.Purge()
+	c.now = 0
+}
+
+// Resize scales the history table to the new size. When shrinking, keys are
+// evicted as usual until they fit.
+func (c *lruK[K, V]) Resize(size int) {
+	c.size = size
+	for c.resident.Len() > c.size {
+		c.evict()
+	}
+	_ = c.history.resize(size)
+}
+
+// request records a new request in the history of a key. The key starts a
+// new correlated reference period, so it leaves the heap until it's over.
+func (c *lruK[K, V]) request(e *lruKEntry[K, V]) {
+	c.unlink(e)
+	_ = c.correlated.insert(e.key, e)
+	c.now++
+	h := e.history
+	if h.last > 0 && c.now-h.last <= c.correlatedPeriod {
+		h.last = c.now
+		return
+	}
+	// The correlated requests which followed the previous uncorrelated one
+	// are collapsed into it, so the older requests are moved forward by the
+	// length of that period.
+	correlated := h.last - h.requests[0]
+	for i := len(h.requests) - 1; i > 0; i-- {
+		if h.requests[i-1] > 0 {
+			h.requests[i] = h.requests[i-1] + correlated
+		}
+	}
+	h.requests[0] = c.now
+	h.last = c.now
+}
+
+// evict drops the key whose K-th most recent request is the oldest, among
+// the keys outside of their correlated reference period. Keys with fewer
+// than K requests come first. Ties, and the case where all the keys are in
+// their correlated reference period, are broken by evicting the least
+// recently requested key. The history of the evicted key is remembered.
+func (c *lruK[K, V]) evict() {
+	// correlated is ordered by the last request, so the keys whose period is
+	// over are at its end.
+	for node := c.correlated.last; node != nil && c.now-node.value.history.last > c.correlatedPeriod; node = c.correlated.last {
+		_ = c.correlated.remove(node.key)
+		heap.Push(&c.heap, node.value)
+	}
+	var victim *lruKEntry[K, V]
+	if len(c.heap) > 0 {
+		victim = heap.Pop(&c.heap).(*lruKEntry[K, V])
+	} else {
+		victim = c.correlated.remove(c.correlated.last.key).value
+	}
+	_ = c.resident.remove(victim.key)
+	_, _ = c.history.write(victim.key, victim.history)
+	c.notifyEvict(victim.key, victim.value)
+}
+
+// unlink removes the entry from the heap or from correlated, whichever holds it.
+func (c *lruK[K, V]) unlink(e *lruKEntry[K, V]) {
+	if e.index >= 0 {
+		heap.Remove(&c.heap, e.index)
+		e.index = -1
+		return
+	}
+	_ = c.correlated.remove(e.key)
+}
+
+// lruKHeap implements heap.Interface and keeps the entry whose K-th most
+// recent request is the oldest at the head of the slice, the least recently
+// requested one on ties.
+type lruKHeap[K comparable, V any] []*lruKEntry[K, V]
+
+func (h lruKHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h lruKHeap[K, V]) Less(i, j int) bool {
+	a, b := h[i].history, h[j].history
+	if a.requests[len(a.requests)-1] == b.requests[len(b.requests)-1] {
+		return a.last < b.last
+	}
+	return a.requests[len(a.requests)-1] < b.requests[len(b.requests)-1]
+}
+
+func (h lruKHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lruKHeap[K, V]) Push(x interface{}) {
+	e := x.(*lruKEntry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lruKHeap[K, V]) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	e.index = -1
+	return e
+}
+
+type lruKState[K comparable] struct {
+	keys    []K // resident keys from the most to the least recently requested
+	history []K // non-resident keys from the most to the least recently evicted
+}
+
+func (c *lruK[K, V]) state() lruKState[K] {
+	return lruKState[K]{
+		keys:    c.resident.Keys(),
+		history: c.history.Keys(),
+	}
+}
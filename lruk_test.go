@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLRUK(t *testing.T) {
+	t.Run("keys are evicted by their k-th most recent request", func(t *testing.T) {
+		type step struct {
+			write    bool
+			key      int
+			hit      bool // only checked for reads
+			expected lruKState[int]
+			evicted  []int // keys evicted by the step
+		}
+		steps := []step{ // size=3, k=2
+			{write: true, key: 1, expected: lruKState[int]{keys: []int{1}}},
+			{write: true, key: 2, expected: lruKState[int]{keys: []int{2, 1}}},
+			{write: true, key: 3, expected: lruKState[int]{keys: []int{3, 2, 1}}},
+			{key: 1, hit: true, expected: lruKState[int]{keys: []int{1, 3, 2}}},
+			// 2 and 3 were requested once, 2 is the least recently used of them.
+			{write: true, key: 4, evicted: []int{2}, expected: lruKState[int]{keys: []int{4, 1, 3}, history: []int{2}}},
+			// 2 is requested for the second time, thanks to its history.
+			{write: true, key: 2, evicted: []int{3}, expected: lruKState[int]{keys: []int{2, 4, 1}, history: []int{3}}},
+			// 4 is the only key requested once.
+			{write: true, key: 5, evicted: []int{4}, expected: lruKState[int]{keys: []int{5, 2, 1}, history: []int{4, 3}}},
+			// 1's second most recent request is older than 2's.
+			{key: 5, hit: true, expected: lruKState[int]{keys: []int{5, 2, 1}, history: []int{4, 3}}},
+			{write: true, key: 6, evicted: []int{1}, expected: lruKState[int]{keys: []int{6, 5, 2}, history: []int{1, 4, 3}}},
+		}
+		c := newLRUK[int, int](3, 2, 0)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i, step := range steps {
+			evicted = evicted[:0]
+			if step.write {
+				c.Write(step.key, step.key*10)
+			} else if value, isCacheMiss := c.Read(step.key); isCacheMiss == step.hit || (step.hit && value != step.key*10) {
+				t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+			}
+			if state := c.state(); !equalStates(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("correlated requests count as one", func(t *testing.T) {
+		for _, tc := range []struct {
+			correlatedPeriod int
+			evicted          int
+		}{
+			// 1 was requested twice, 2 only once.
+			{0, 2},
+			// 1's second request is correlated and 2 is still in its correlated period.
+			{1, 1},
+		} {
+			c := newLRUK[int, int](2, 2, tc.correlatedPeriod)
+			evicted := []int{}
+			c.SetEvictHandler(func(key, value int) {
+				evicted = append(evicted, key)
+			})
+			c.Write(1, 10)
+			_, _ = c.Read(1)
+			c.Write(2, 20)
+			c.Write(3, 30)
+			if !reflect.DeepEqual(evicted, []int{tc.evicted}) {
+				t.Fatalf("expected key %d to be evicted with a correlated period of %d but got %#v", tc.evicted, tc.correlatedPeriod, evicted)
+			}
+		}
+	})
+	t.Run("keys in their correlated period are evicted when there's nothing else", func(t *testing.T) {
+		c := newLRUK[int, int](2, 2, 10)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		if state := c.state(); !equalStates(state, lruKState[int]{keys: []int{3, 2}, history: []int{1}}) {
+			t.Fatalf("expected the least recently used key to be evicted but got %#v", state)
+		}
+	})
+	t.Run("deleted keys are not evicted", func(t *testing.T) {
+		c := newLRUK[int, int](2, 2, 0)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		if !c.Delete(2) {
+			t.Fatalf("expected 2 to be resident")
+		}
+		c.Write(4, 40)
+		c.Write(5, 50)
+		if state := c.state(); !equalStates(state, lruKState[int]{keys: []int{5, 4}, history: []int{3, 1}}) {
+			t.Fatalf("unexpected state %#v after deleting a key", state)
+		}
+		c.Write(6, 60)
+		if state := c.state(); !equalStates(state, lruKState[int]{keys: []int{6, 5}, history: []int{4, 3}}) {
+			t.Fatalf("unexpected state %#v after deleting a key", state)
+		}
+	})
+	t.Run("the history table is as large as the cache", func(t *testing.T) {
+		c := newLRUK[int, int](4, 2, 0)
+		for key := 0; key < 10; key++ {
+			c.Write(key, key)
+		}
+		c.Resize(2)
+		state := c.state()
+		if !equalStates(state, lruKState[int]{keys: []int{9, 8}, history: []int{7, 6}}) {
+			t.Fatalf("unexpected state %#v after shrinking", state)
+		}
+		if c.Delete(7) || !c.Delete(9) || c.Len() != 1 || len(c.state().history) != 1 {
+			t.Fatalf("unexpected state %#v after deletes", c.state())
+		}
+	})
+	t.Run("hit rate beats lru when scans go through the cache", func(t *testing.T) {
+		expectHigherHitRate(t, scanTrace(50, 60, 50), 100, LRUK, LRU)
+	})
+}
@@ -1,31 +1,32 @@
 package cache
 
-func newMRU(size int) *mru {
-	return &mru{
+func newMRU[K comparable, V any](size int) *mru[K, V] {
+	return &mru[K, V]{
 		size: size,
 		head: nil,
 		last: nil,
-		hash: make(map[int]*mruNode),
+		hash: make(map[K]*mruNode[K, V]),
 	}
 }
 
-// mru implements Cache
+// mru implements Policy
 // MRU evicts the most recently used key, ie. the key that was just requested.
-type mru struct {
+type mru[K comparable, V any] struct {
+	evictNotifier[K, V]
 	size int
-	head *mruNode
-	last *mruNode
-	hash map[int]*mruNode
+	head *mruNode[K, V]
+	last *mruNode[K, V]
+	hash map[K]*mruNode[K, V]
 }
 
-type mruNode struct {
-	key      int
-	value    int
-	next     *mruNode
-	previous *mruNode
+type mruNode[K comparable, V any] struct {
+	key      K
+	value    V
+	next     *mruNode[K, V]
+	previous *mruNode[K, V]
 }
 
-func (m *mru) Write(key, value int) {
+func (m *mru[K, V]) Write(key K, value V) {
 	if node, exists := m.hash[key]; exists {
 		node.value = value
 		m.promote(key)
@@ -34,7 +35,7 @@ func (m *mru) Write(key, value int) {
 	if m.size == len(m.hash) {
 		m.evict()
 	}
-	node := &mruNode{
+	node := &mruNode[K, V]{
 		key:   key,
 		value: value,
 	}
@@ -50,18 +51,58 @@ func (m *mru) Write(key, value int) {
 	m.hash[key] = node
 }
 
-func (m *mru) Read(key int) (value int, isCacheMiss bool) {
+func (m *mru[K, V]) Read(key K) (value V, isCacheMiss bool) {
 	if node, exists := m.hash[key]; exists {
 		// to evict the key we just read, we promote it then evict the head.
 		m.promote(key)
 		m.evict()
 		return node.value, false
 	}
-	return 0, true
+	return value, true
+}
+
+func (m *mru[K, V]) Peek(key K) (value V, found bool) {
+	if node, exists := m.hash[key]; exists {
+		return node.value, true
+	}
+	return value, false
+}
+
+func (m *mru[K, V]) Delete(key K) bool {
+	return m.remove(key) != nil
+}
+
+func (m *mru[K, V]) Len() int {
+	return len(m.hash)
+}
+
+func (m *mru[K, V]) Capacity() int {
+	return m.size
+}
+
+func (m *mru[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.hash))
+	for node := m.head; node != nil; node = node.next {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
+func (m *mru[K, V]) Purge() {
+	m.head = nil
+	m.last = nil
+	m.hash = make(map[K]*mruNode[K, V])
+}
+
+func (m *mru[K, V]) Resize(size int) {
+	m.size = size
+	for len(m.hash) > m.size {
+		m.evict()
+	}
 }
 
 // promote makes the node matching the given key, the head of the doubly-linked list.
-func (m *mru) promote(key int) {
+func (m *mru[K, V]) promote(key K) {
 	node, exists := m.hash[key]
 	if !exists {
 		return
@@ -82,25 +123,40 @@ func (m *mru) promote(key int) {
 	}
 	node.previous.next = node.next
 	node.next.previous = node.previous
+	node.previous = nil
 	m.head.previous = node
 	node.next = m.head
 	m.head = node
 }
 
-// evict pops the head of the doubly-linked list.
-// evict assumes you check that the list is not empty before you called it.
-func (m *mru) evict() {
-	if len(m.hash) == 0 {
+// evict pops the head of the doubly-linked list and reports it as evicted.
+func (m *mru[K, V]) evict() {
+	if m.head == nil {
 		return
 	}
-	if len(m.hash) == 1 {
-		node := m.head
-		m.head = nil
-		m.last = nil
-		delete(m.hash, node.key)
-		return
+	if node := m.remove(m.head.key); node != nil {
+		m.notifyEvict(node.key, node.value)
+	}
+}
+
+// remove detaches the node matching the given key from the doubly-linked list.
+func (m *mru[K, V]) remove(key K) *mruNode[K, V] {
+	node, exists := m.hash[key]
+	if !exists {
+		return nil
+	}
+	if node.previous == nil {
+		m.head = node.next
+	} else {
+		node.previous.next = node.next
+	}
+	if node.next == nil {
+		m.last = node.previous
+	} else {
+		node.next.previous = node.previous
 	}
-	delete(m.hash, m.head.key)
-	m.head.next.previous = nil
-	m.head = m.head.next
+	node.next = nil
+	node.previous = nil
+	delete(m.hash, key)
+	return node
 }
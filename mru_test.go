@@ -7,7 +7,7 @@ import (
 func TestMRU(t *testing.T) {
 	t.Run("base-case caches have correct states", func(t *testing.T) {
 		// That is, empty cache and cache with one node.
-		c := newMRU(2)
+		c := newMRU[int, int](2)
 		if len(c.hash) != 0 || c.head != nil || c.last != nil || c.size != 2 {
 			t.Fatalf("incorrect empty cache state: %#v", c)
 		}
@@ -21,7 +21,7 @@ func TestMRU(t *testing.T) {
 		}
 	})
 	t.Run("cache correctly evicts most recently requested key", func(t *testing.T) {
-		c := newMRU(2)
+		c := newMRU[int, int](2)
 		c.Write(1, 10)
 		c.Write(2, 20)
 		if len(c.hash) != 2 || c.head.key != 2 || c.last.key != 1 {
@@ -32,4 +32,46 @@ func TestMRU(t *testing.T) {
 			t.Fatalf("unexpected cache state after an eviction: head=%#v, last=%#v", c.head, c.last)
 		}
 	})
+	t.Run(".Delete() correctly unlinks a key in the middle of the linked list", func(t *testing.T) {
+		c := newMRU[int, int](3)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		if !c.Delete(2) || c.Delete(2) {
+			t.Fatal("expected key 2 to be deleted exactly once")
+		}
+		if len(c.hash) != 2 || c.head.key != 3 || c.last.key != 1 ||
+			c.head.next != c.last || c.last.previous != c.head {
+			t.Fatalf("unexpected cache state after a delete: head=%#v, last=%#v", c.head, c.last)
+		}
+	})
+	t.Run("keys read from the middle of the linked list become the head", func(t *testing.T) {
+		c, err := New[int, int](MRU, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(2)
+		if err := c.Resize(1); err != nil || c.Len() != 1 || !c.Contains(1) {
+			t.Fatalf("expected only key 1 to be left after shrinking, len=%d, err=%v", c.Len(), err)
+		}
+	})
+	t.Run("evictions are reported to the eviction handler", func(t *testing.T) {
+		evicted := []int{}
+		c := Factory[int, int](MRU, 3, OnEvict(func(key, value int, reason EvictionReason) {
+			if reason == EvictionCapacity {
+				evicted = append(evicted, key)
+			}
+		}))
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(2)
+		_ = c.Resize(1)
+		if len(evicted) != 2 || evicted[0] != 2 || evicted[1] != 3 {
+			t.Fatalf("expected keys 2 and 3 to be evicted but got %#v", evicted)
+		}
+	})
 }
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/heap"
+	"math"
+)
+
+func newOPT[K comparable, V any](size int, trace []K) *opt[K, V] {
+	c := &opt[K, V]{
+		size:   size,
+		hash:   make(map[K]*optEntry[K, V]),
+		future: make(map[K][]int),
+	}
+	for i, key := range trace {
+		c.future[key] = append(c.future[key], i)
+	}
+	return c
+}
+
+// opt implements Policy
+// It follows Belady's optimal algorithm, also known as MIN, as described in
+// "A study of replacement algorithms for a virtual-storage computer" by
+// L. A. Belady.
+//
+// OPT knows the whole trace of requests in advance and evicts the key which
+// will be requested again the furthest in the future, if ever. A new key
+// which will be requested later than all the keys in the cache is not
+// admitted at all, it's reported as evicted right away. No online strategy
+// can do better, so OPT gives an upper bound of the hit rate for a trace.
+//
+// Every Read consumes the next request of its key in the trace, Writes don't.
+// So the cache must be used the way simulations use it: read the keys in the
+// order of the trace and write the missing ones.
+type opt[K comparable, V any] struct {
+	evictNotifier[K, V]
+	size   int
+	hash   map[K]*optEntry[K, V]
+	heap   optHeap[K, V]
+	future map[K][]int // positions of the upcoming requests of each key in the trace
+}
+
+type optEntry[K comparable, V any] struct {
+	key         K
+	value       V
+	nextRequest int // position of the next request in the trace, math.MaxInt if there is none
+	index       int // index of the entry in the heap
+}
+
+func (c *opt[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	c.consume(key)
+	e, found := c.hash[key]
+	if !found {
+		return value, true
+	}
+	e.nextRequest = c.nextRequest(key)
+	heap.Fix(&c.heap, e.index)
+	return e.value, false
+}
+
+func (c *opt[K, V]) Write(key K, value V) {
+	if e, found := c.hash[key]; found {
+		e.value = value
+		return
+	}
+	e := &optEntry[K, V]{key: key, value: value, nextRequest: c.nextRequest(key)}
+	if len(c.heap) >= c.size {
+		if e.nextRequest >= c.heap[0].nextRequest {
+			c.notifyEvict(key, value)
+			return
+		}
+		c.evict()
+	}
+	c.hash[key] = e
+	heap.Push(&c.heap, e)
+}
+
+func (c *opt[K, V]) Peek(key K) (value V, found bool) {
+	if e, found := c.hash[key]; found {
+		return e.value, true
+	}
+	return value, false
+}
+
+func (c *opt[K, V]) Delete(key K) bool {
+	e, found := c.hash[key]
+	if !found {
+		return false
+	}
+	heap.Remove(&c.heap, e.index)
+	delete(c.hash, key)
+	return true
+}
+
+func (c *opt[K, V]) Len() int {
+	return len(c.hash)
+}
+
+func (c *opt[K, V]) Capacity() int {
+	return c.size
+}
+
+func (c *opt[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.heap))
+	for _, e := range c.heap {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Purge drops the entries, the requests consumed from the trace stay consumed.
+func (c *opt[K, V]) Purge() {
+	c.hash = make(map[K]*optEntry[K, V])
+	c.heap = nil
+}
+
+func (c *opt[K, V]) Resize(size int) {
+	c.size = size
+	for len(c.heap) > c.size {
+		c.evict()
+	}
+}
+
+// evict drops the key which will be requested the furthest in the future.
+func (c *opt[K, V]) evict() {
+	e := heap.Pop(&c.heap).(*optEntry[K, V])
+	delete(c.hash, e.key)
+	c.notifyEvict(e.key, e.value)
+}
+
+// consume moves past the current request of the key in the trace.
+func (c *opt[K, V]) consume(key K) {
+	positions, found := c.future[key]
+	if !found {
+		return
+	}
+	if len(positions) == 1 {
+		delete(c.future, key)
+		return
+	}
+	c.future[key] = positions[1:]
+}
+
+func (c *opt[K, V]) nextRequest(key K) int {
+	if positions, found := c.future[key]; found {
+		return positions[0]
+	}
+	return math.MaxInt
+}
+
+// optHeap implements heap.Interface and keeps the entry which will be
+// requested the furthest in the future at the head of the slice.
+type optHeap[K comparable, V any] []*optEntry[K, V]
+
+func (h optHeap[K, V]) Len() int {
+	return len(h)
+}
+
+func (h optHeap[K, V]) Less(i, j int) bool {
+	return h[i].nextRequest > h[j].nextRequest
+}
+
+func (h optHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *optHeap[K, V]) Push(x interface{}) {
+	e := x.(*optEntry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *optHeap[K, V]) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
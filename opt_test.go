@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOPT(t *testing.T) {
+	t.Run("evicts the key requested the furthest in the future", func(t *testing.T) {
+		trace := []int{1, 2, 3, 4, 1, 2, 4, 3}
+		c := newOPT[int, int](3, trace)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		hits := []int{}
+		for _, key := range trace {
+			if _, isCacheMiss := c.Read(key); isCacheMiss {
+				c.Write(key, key*10)
+			} else {
+				hits = append(hits, key)
+			}
+		}
+		// 4 replaces 3, which is requested last, then 3 isn't admitted back
+		// because it's never requested again.
+		if !reflect.DeepEqual(hits, []int{1, 2, 4}) || !reflect.DeepEqual(evicted, []int{3, 3}) {
+			t.Fatalf("unexpected hits %#v or evictions %#v", hits, evicted)
+		}
+	})
+	t.Run("keys requested later than all the others are not admitted", func(t *testing.T) {
+		trace := []int{1, 2, 3, 1, 2, 3}
+		c := newOPT[int, int](2, trace)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		hits := 0
+		for _, key := range trace {
+			if _, isCacheMiss := c.Read(key); isCacheMiss {
+				c.Write(key, key*10)
+			} else {
+				hits++
+			}
+		}
+		if hits != 2 || !reflect.DeepEqual(evicted, []int{3, 3}) || !reflect.DeepEqual(c.Keys(), []int{1, 2}) {
+			t.Fatalf("unexpected hits %d, evictions %#v or keys %#v", hits, evicted, c.Keys())
+		}
+	})
+	t.Run("no strategy beats it", func(t *testing.T) {
+		trace := skewedTrace(6, 1000, 20000, false)
+		optimal := hitRate(OPT, 50, trace, WithTrace(trace))
+		for _, algorithm := range Algorithms() {
+			if actual := hitRate(algorithm, 50, trace); actual > optimal {
+				t.Fatalf("%s has a hit rate of %f, better than opt's %f", algorithm, actual, optimal)
+			}
+		}
+	})
+	t.Run("needs the trace of requests", func(t *testing.T) {
+		var invalid *InvalidOptionError
+		if _, err := New[int, int](OPT, 10); !errors.As(err, &invalid) || invalid.Option != "WithTrace" {
+			t.Fatalf("expected opt to need a trace but got %v", err)
+		}
+		for _, algorithm := range Algorithms() {
+			if algorithm == OPT {
+				t.Fatal("expected opt to be left out of the algorithms")
+			}
+		}
+	})
+}
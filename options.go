@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultSegmentRatio splits the capacity of segmented strategies in halves.
+	defaultSegmentRatio = 0.5
+	// defaultSegments makes SLRUN behave like SLRU.
+	defaultSegments = 2
+)
+
+// Option configures the caches produced by New and Factory.
+type Option func(*settings)
+
+// settings collects the configuration applied by a list of options.
+type settings struct {
+	concurrent       bool
+	shards           int
+	ttl              time.Duration
+	clock            Clock
+	onEvict          interface{} // func(key K, value V, reason EvictionReason)
+	segmentRatio     float64
+	segments         int
+	kin, kout        float64
+	noStats          bool
+	seed             int64
+	samples          int
+	trace            interface{} // []K
+	k                int
+	correlatedPeriod int
+}
+
+func newSettings(opts []Option) *settings {
+	s := &settings{
+		clock:            systemClock{},
+		segmentRatio:     defaultSegmentRatio,
+		segments:         defaultSegments,
+		kin:              defaultKin,
+		kout:             defaultKout,
+		seed:             time.Now().UnixNano(),
+		samples:          defaultSamples,
+		k:                defaultK,
+		correlatedPeriod: defaultCorrelatedPeriod,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// validate checks the options which don't depend on the cache's types.
+func (s *settings) validate() error {
+	switch {
+	case s.shards < 0:
+		return &InvalidOptionError{Option: "WithShards", Reason: fmt.Sprintf("negative number of shards %d", s.shards)}
+	case s.ttl < 0:
+		return &InvalidOptionError{Option: "WithTTL", Reason: fmt.Sprintf("negative ttl %s", s.ttl)}
+	case s.clock == nil:
+		return &InvalidOptionError{Option: "WithClock", Reason: "nil clock"}
+	case !(s.segmentRatio > 0 && s.segmentRatio < 1):
+		return &InvalidOptionError{Option: "WithSegmentRatio", Reason: fmt.Sprintf("ratio %v is not between 0 and 1", s.segmentRatio)}
+	case s.segments < 1:
+		return &InvalidOptionError{Option: "WithSegments", Reason: fmt.Sprintf("unsupported number of segments %d", s.segments)}
+	case !(s.kin > 0 && s.kin < 1):
+		return &InvalidOptionError{Option: "WithTwoQueueRatios", Reason: fmt.Sprintf("kin %v is not between 0 and 1", s.kin)}
+	case !(s.kout > 0):
+		return &InvalidOptionError{Option: "WithTwoQueueRatios", Reason: fmt.Sprintf("kout %v is not positive", s.kout)}
+	case s.samples < 1:
+		return &InvalidOptionError{Option: "WithSamples", Reason: fmt.Sprintf("unsupported number of samples %d", s.samples)}
+	case s.k < 1:
+		return &InvalidOptionError{Option: "WithLRUK", Reason: fmt.Sprintf("unsupported k %d", s.k)}
+	case s.correlatedPeriod < 0:
+		return &InvalidOptionError{Option: "WithLRUK", Reason: fmt.Sprintf("negative correlated reference period %d", s.correlatedPeriod)}
+	}
+	return nil
+}
+
+// evictHandler returns the handler registered with OnEvict, if any,
+// or an error if its key and value types don't match the cache's.
+func evictHandler[K comparable, V any](s *settings) (func(key K, value V, reason EvictionReason), error) {
+	if s.onEvict == nil {
+		return nil, nil
+	}
+	onEvict, ok := s.onEvict.(func(key K, value V, reason EvictionReason))
+	if !ok {
+		return nil, &InvalidOptionError{
+			Option: "OnEvict",
+			Reason: fmt.Sprintf("handler %T does not match the cache's key and value types", s.onEvict),
+		}
+	}
+	return onEvict, nil
+}
+
+// traceOf returns the trace given to WithTrace, if any, or an error if its
+// key type doesn't match the cache's or if OPT didn't get one.
+func traceOf[K comparable](algorithm string, s *settings) ([]K, error) {
+	if s.trace == nil {
+		if algorithm == OPT {
+			return nil, &InvalidOptionError{Option: "WithTrace", Reason: "OPT needs the whole trace of requests"}
+		}
+		return nil, nil
+	}
+	trace, ok := s.trace.([]K)
+	if !ok {
+		return nil, &InvalidOptionError{
+			Option: "WithTrace",
+			Reason: fmt.Sprintf("trace %T does not match the cache's key type", s.trace),
+		}
+	}
+	return trace, nil
+}
+
+// WithConcurrency makes the cache safe for use by multiple goroutines.
+func WithConcurrency() Option {
+	return func(s *settings) {
+		s.concurrent = true
+	}
+}
+
+// WithShards splits the cache capacity between n independent instances of the
+// same strategy and distributes keys between them by hash. Each shard is
+// guarded by its own lock, so the resulting cache is safe for concurrent use
+// and scales better than WithConcurrency on many cores. Note that the
+// replacement policy is only applied within each shard.
+func WithShards(n int) Option {
+	return func(s *settings) {
+		s.shards = n
+	}
+}
+
+// WithTTL sets the time to live of the values stored with Write.
+// By default values never expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock replaces the wall clock used to expire entries.
+func WithClock(clock Clock) Option {
+	return func(s *settings) {
+		s.clock = clock
+	}
+}
+
+// OnEvict registers a function to call whenever an entry leaves the cache.
+// The key and value types of fn must match the ones of the cache.
+// The function is called synchronously, so it should not call back into the cache.
+func OnEvict[K comparable, V any](fn func(key K, value V, reason EvictionReason)) Option {
+	return func(s *settings) {
+		s.onEvict = fn
+	}
+}
+
+// WithSegmentRatio sets the share of the capacity given to the segment which
+// holds the most valuable entries in segmented strategies, ie. protected in
+// SLRU and privileged in LFRU. The ratio must be strictly between 0 and 1 and
+// defaults to 0.5. Because every segment needs at least one slot, extreme
+// ratios raise the minimum size of the cache.
+func WithSegmentRatio(ratio float64) Option {
+	return func(s *settings) {
+		s.segmentRatio = ratio
+	}
+}
+
+// WithSegments sets the number of lru segments of SLRUN, two by default.
+// The capacity is split evenly between segments and each of them needs at
+// least one slot, so the cache can't be smaller than the number of segments.
+func WithSegments(n int) Option {
+	return func(s *settings) {
+		s.segments = n
+	}
+}
+
+// WithTwoQueueRatios sets the thresholds of 2Q relative to the capacity.
+// kin is the share of the capacity above which keys requested only once are
+// evicted first. It must be between 0 and 1 and defaults to 0.25. kout is the
+// number of evicted keys remembered by 2Q. It must be positive and defaults
+// to 0.5. Both thresholds are at least one key.
+func WithTwoQueueRatios(kin, kout float64) Option {
+	return func(s *settings) {
+		s.kin, s.kout = kin, kout
+	}
+}
+
+// WithSeed seeds the random number generator of Random, SampledLRU and
+// SampledLFU, so that simulations are reproducible. By default the seed
+// changes every time a cache is built.
+func WithSeed(seed int64) Option {
+	return func(s *settings) {
+		s.seed = seed
+	}
+}
+
+// WithSamples sets the number of keys SampledLRU and SampledLFU pick at
+// random to evict the worst of them, five by default. More samples evict
+// better keys at the cost of slower writes.
+func WithSamples(k int) Option {
+	return func(s *settings) {
+		s.samples = k
+	}
+}
+
+// WithTrace gives OPT the whole trace of requests, in order, so that it
+// knows which key will be requested the furthest in the future. The key
+// type of the trace must match the one of the cache. Other strategies
+// ignore it.
+func WithTrace[K comparable](trace []K) Option {
+	return func(s *settings) {
+		s.trace = trace
+	}
+}
+
+// WithLRUK sets the number of requests remembered by LRU-K for each key,
+// two by default, and its correlated reference period, counted in requests
+// to the cache. Requests of a key which follow its previous one within the
+// period count as a single request. The period is zero by default, so that
+// all the requests count.
+func WithLRUK(k, correlatedPeriod int) Option {
+	return func(s *settings) {
+		s.k, s.correlatedPeriod = k, correlatedPeriod
+	}
+}
+
+// WithoutStats stops the cache from maintaining the counters reported by Stats.
+func WithoutStats() Option {
+	return func(s *settings) {
+		s.noStats = true
+	}
+}
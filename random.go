@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"math/rand"
+)
+
+// defaultSamples is the number of keys sampled by SampledLRU and SampledLFU, like in Redis.
+const defaultSamples = 5
+
+// sampleBy tells which of the sampled keys gets evicted.
+type sampleBy int
+
+const (
+	sampleByRecency   sampleBy = iota // the least recently used key
+	sampleByFrequency                 // the least frequently used key, then the least recently used
+)
+
+func newRandom[K comparable, V any](size, samples int, by sampleBy, seed int64) *random[K, V] {
+	return &random[K, V]{
+		size:    size,
+		samples: samples,
+		by:      by,
+		rnd:     rand.New(rand.NewSource(seed)),
+		index:   make(map[K]int),
+	}
+}
+
+// random implements Policy
+// It evicts the worst of a few keys picked at random, either by recency or
+// by frequency, the way Redis approximates LRU and LFU without maintaining
+// any list. Picking a single key makes it Random replacement. More samples
+// get closer to LRU or LFU, but keys are picked with replacement, so even as
+// many samples as keys don't guarantee to find the exact LRU or LFU victim.
+//
+// The keys are kept in a slice so that picking one is a constant time
+// operation, deleting a key moves the last one into its place.
+type random[K comparable, V any] struct {
+	evictNotifier[K, V]
+	size    int
+	samples int
+	by      sampleBy
+	rnd     *rand.Rand
+	entries []*randomEntry[K, V]
+	index   map[K]int // position of the keys in entries
+	tick    uint64    // logical time of the last request
+}
+
+type randomEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	lastAccess uint64
+	frequency  int
+}
+
+func (c *random[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	i, found := c.index[key]
+	if !found {
+		return value, true
+	}
+	c.touch(c.entries[i])
+	return c.entries[i].value, false
+}
+
+func (c *random[K, V]) Write(key K, value V) {
+	if i, found := c.index[key]; found {
+		c.entries[i].value = value
+		c.touch(c.entries[i])
+		return
+	}
+	if len(c.entries) >= c.size {
+		c.evict()
+	}
+	c.tick++
+	c.index[key] = len(c.entries)
+	c.entries = append(c.entries, &randomEntry[K, V]{key: key, value: value, lastAccess: c.tick})
+}
+
+func (c *random[K, V]) Peek(key K) (value V, found bool) {
+	if i, found := c.index[key]; found {
+		return c.entries[i].value, true
+	}
+	return value, false
+}
+
+func (c *random[K, V]) Delete(key K) bool {
+	i, found := c.index[key]
+	if !found {
+		return false
+	}
+	c.remove(i)
+	return true
+}
+
+func (c *random[K, V]) Len() int {
+	return len(c.entries)
+}
+
+func (c *random[K, V]) Capacity() int {
+	return c.size
+}
+
+func (c *random[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.entries))
+	for _, e := range c.entries {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (c *random[K, V]) Purge() {
+	c.entries = nil
+	c.index = make(map[K]int)
+}
+
+func (c *random[K, V]) Resize(size int) {
+	c.size = size
+	for len(c.entries) > c.size {
+		c.evict()
+	}
+}
+
+func (c *random[K, V]) touch(e *randomEntry[K, V]) {
+	c.tick++
+	e.lastAccess = c.tick
+	e.frequency++
+}
+
+// evict picks samples keys at random, possibly the same one more than
+// once, and removes the worst of them.
+func (c *random[K, V]) evict() {
+	victim := c.rnd.Intn(len(c.entries))
+	for i := 1; i < c.samples; i++ {
+		if candidate := c.rnd.Intn(len(c.entries)); c.worse(c.entries[candidate], c.entries[victim]) {
+			victim = candidate
+		}
+	}
+	e := c.entries[victim]
+	c.remove(victim)
+	c.notifyEvict(e.key, e.value)
+}
+
+// worse tells whether a should be evicted rather than b.
+func (c *random[K, V]) worse(a, b *randomEntry[K, V]) bool {
+	if c.by == sampleByFrequency && a.frequency != b.frequency {
+		return a.frequency < b.frequency
+	}
+	return a.lastAccess < b.lastAccess
+}
+
+// remove moves the last entry in place of the i-th one.
+func (c *random[K, V]) remove(i int) {
+	last := len(c.entries) - 1
+	delete(c.index, c.entries[i].key)
+	if i != last {
+		c.entries[i] = c.entries[last]
+		c.index[c.entries[i].key] = i
+	}
+	c.entries[last] = nil
+	c.entries = c.entries[:last]
+}
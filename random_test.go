@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	t.Run("evictions are reproducible with the same seed", func(t *testing.T) {
+		replay := func(algorithm string, seed int64) []int {
+			evicted := []int{}
+			c := Factory[int, int](algorithm, 10, WithSeed(seed), OnEvict(func(key, value int, reason EvictionReason) {
+				evicted = append(evicted, key)
+			}))
+			for key := 0; key < 100; key++ {
+				_, _ = c.Read(key % 7)
+				c.Write(key, key)
+			}
+			return evicted
+		}
+		for _, algorithm := range []string{Random, SampledLRU, SampledLFU} {
+			first, second := replay(algorithm, 42), replay(algorithm, 42)
+			if len(first) != 90 || !reflect.DeepEqual(first, second) {
+				t.Fatalf("%s: expected the same evictions with the same seed but got %#v and %#v", algorithm, first, second)
+			}
+			if reflect.DeepEqual(first, replay(algorithm, 43)) {
+				t.Fatalf("%s: expected different evictions with a different seed", algorithm)
+			}
+		}
+	})
+	t.Run("random replacement evicts keys uniformly", func(t *testing.T) {
+		c := newRandom[int, int](4, 1, sampleByRecency, 1)
+		counts := map[int]int{}
+		c.SetEvictHandler(func(key, value int) {
+			counts[key]++
+		})
+		for round := 0; round < 4000; round++ {
+			for key := 0; key < 4; key++ {
+				c.Write(key, key)
+			}
+			c.Write(4, 4)
+			_ = c.Delete(4)
+		}
+		for key := 0; key < 4; key++ {
+			if counts[key] < 800 || counts[key] > 1200 {
+				t.Fatalf("expected about 1000 evictions of each key but got %#v", counts)
+			}
+		}
+	})
+	t.Run("sampling all the keys evicts the least recently used one", func(t *testing.T) {
+		c := newRandom[int, int](3, 50, sampleByRecency, 1)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		c.Write(4, 40)
+		_, _ = c.Read(3)
+		c.Write(5, 50)
+		if !reflect.DeepEqual(evicted, []int{2, 1}) {
+			t.Fatalf("expected keys 2 then 1 to be evicted but got %#v", evicted)
+		}
+	})
+	t.Run("sampling all the keys evicts the least frequently used one", func(t *testing.T) {
+		c := newRandom[int, int](3, 50, sampleByFrequency, 1)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		_, _ = c.Read(1)
+		_, _ = c.Read(2)
+		_, _ = c.Read(3)
+		c.Write(4, 40) // 4 is new, 2 and 3 were requested once, 2 less recently
+		c.Write(5, 50) // 4 and 5 were never requested
+		if !reflect.DeepEqual(evicted, []int{2, 4}) || !reflect.DeepEqual(c.Keys(), []int{1, 3, 5}) {
+			t.Fatalf("unexpected evictions %#v or keys %#v", evicted, c.Keys())
+		}
+	})
+	t.Run("deleting a key moves the last one in its place", func(t *testing.T) {
+		c := newRandom[int, int](4, 1, sampleByRecency, 1)
+		for key := 1; key <= 4; key++ {
+			c.Write(key, key*10)
+		}
+		if !c.Delete(2) || c.Delete(2) || !reflect.DeepEqual(c.Keys(), []int{1, 4, 3}) {
+			t.Fatalf("unexpected keys %#v", c.Keys())
+		}
+		if value, found := c.Peek(4); !found || value != 40 {
+			t.Fatalf("expected to find key 4 but got value=%d, found=%t", value, found)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Constructor builds a custom replacement strategy which can hold size entries.
+// Custom strategies store keys and values as interface{}, the caches built
+// by New convert them back to their own key and value types.
+type Constructor func(size int) Policy[any, any]
+
+var (
+	registryMu sync.RWMutex
+	// constructors maps names to strategies. Builtin strategies have no
+	// constructor because they are built for the cache's types, see newStrategy.
+	constructors = map[string]Constructor{
+		LRU:        nil,
+		LFU:        nil,
+		LFUList:    nil,
+		MRU:        nil,
+		SLRU:       nil,
+		LFRU:       nil,
+		ARC:        nil,
+		SLRUN:      nil,
+		WTinyLFU:   nil,
+		TwoQ:       nil,
+		LIRS:       nil,
+		CLOCK:      nil,
+		GCLOCK:     nil,
+		CLOCKPro:   nil,
+		CAR:        nil,
+		S3FIFO:     nil,
+		SIEVE:      nil,
+		FIFO:       nil,
+		Random:     nil,
+		SampledLRU: nil,
+		SampledLFU: nil,
+		LRUK:       nil,
+		GDSF:       nil,
+		GreedyDual: nil,
+		// OPT needs the future requests, see WithTrace, so it's left out of names.
+		OPT: nil,
+	}
+	// names keeps the strategies in registration order.
+	names = []string{LRU, LFU, LFUList, MRU, SLRU, LFRU, ARC, SLRUN, WTinyLFU, TwoQ, LIRS, CLOCK, GCLOCK, CLOCKPro, CAR, S3FIFO, SIEVE, FIFO, Random, SampledLRU, SampledLFU, LRUK, GDSF, GreedyDual}
+)
+
+// Register makes a custom replacement strategy available to New and Factory
+// under the given name. Registered strategies are listed by Algorithms, so
+// they show up in the hit-rate tool and the benchmarks. It panics if the
+// name is already taken or if constructor is nil.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if constructor == nil {
+		panic("cache: Register constructor is nil")
+	}
+	if _, dup := constructors[name]; dup {
+		panic(fmt.Sprintf("cache: Register called twice for strategy %s", name))
+	}
+	constructors[name] = constructor
+	names = append(names, name)
+}
+
+// Algorithms returns the names of all the strategies supported by New,
+// the builtin ones first, then the registered ones in registration order.
+// OPT is left out because it only works offline, with WithTrace.
+func Algorithms() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), names...)
+}
+
+// lookup returns the constructor of a strategy, which is nil for builtin ones.
+func lookup(name string) (constructor Constructor, found bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	constructor, found = constructors[name]
+	return constructor, found
+}
+
+// adapter implements Policy
+// It converts the keys and values of a custom strategy to the cache's types.
+type adapter[K comparable, V any] struct {
+	policy Policy[any, any]
+}
+
+func (a adapter[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	v, isCacheMiss := a.policy.Read(key)
+	if isCacheMiss {
+		return value, true
+	}
+	return v.(V), false
+}
+
+func (a adapter[K, V]) Write(key K, value V) {
+	a.policy.Write(key, value)
+}
+
+func (a adapter[K, V]) Peek(key K) (value V, found bool) {
+	v, found := a.policy.Peek(key)
+	if !found {
+		return value, false
+	}
+	return v.(V), true
+}
+
+func (a adapter[K, V]) Delete(key K) bool {
+	return a.policy.Delete(key)
+}
+
+func (a adapter[K, V]) Len() int {
+	return a.policy.Len()
+}
+
+func (a adapter[K, V]) Capacity() int {
+	return a.policy.Capacity()
+}
+
+func (a adapter[K, V]) Keys() []K {
+	keys := make([]K, 0, a.policy.Len())
+	for _, key := range a.policy.Keys() {
+		keys = append(keys, key.(K))
+	}
+	return keys
+}
+
+func (a adapter[K, V]) Purge() {
+	a.policy.Purge()
+}
+
+func (a adapter[K, V]) Resize(size int) {
+	a.policy.Resize(size)
+}
+
+func (a adapter[K, V]) SetEvictHandler(fn func(key K, value V)) {
+	a.policy.SetEvictHandler(func(key, value any) {
+		fn(key.(K), value.(V))
+	})
+}
+
+// WriteWithCost passes the cost and the size on to custom strategies which take them into account.
+func (a adapter[K, V]) WriteWithCost(key K, value V, cost float64, size int) {
+	if p, ok := a.policy.(costAware[any, any]); ok {
+		p.WriteWithCost(key, value, cost, size)
+		return
+	}
+	a.policy.Write(key, value)
+}
+
+// Segments reports the segments of custom strategies which have any.
+func (a adapter[K, V]) Segments() map[string]int {
+	if s, ok := a.policy.(segmented); ok {
+		return s.Segments()
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+// customLRU is registered once per test binary, so the tests can run multiple times.
+const customLRU = "test-custom-lru"
+
+func init() {
+	Register(customLRU, func(size int) Policy[any, any] {
+		return newLRU[any, any](size)
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("builtin strategies are listed first", func(t *testing.T) {
+		builtins := []string{LRU, LFU, LFUList, MRU, SLRU, LFRU, ARC, SLRUN, WTinyLFU, TwoQ, LIRS, CLOCK, GCLOCK, CLOCKPro, CAR,
+			S3FIFO, SIEVE, FIFO, Random, SampledLRU, SampledLFU, LRUK, GDSF, GreedyDual}
+		names := Algorithms()
+		if !reflect.DeepEqual(names[:len(builtins)], builtins) {
+			t.Fatalf("unexpected builtin strategies %#v", names)
+		}
+		if names[len(names)-1] != customLRU {
+			t.Fatalf("expected the registered strategy to be listed last in %#v", names)
+		}
+		for _, name := range names[:len(names)-1] {
+			if constructor, found := lookup(name); !found || constructor != nil {
+				t.Fatalf("expected %s to be a builtin strategy in %#v", name, names)
+			}
+		}
+	})
+	t.Run("registered strategies are built by factory", func(t *testing.T) {
+		evicted := []string{}
+		c := Factory[string, int](customLRU, 2, OnEvict(func(key string, value int, reason EvictionReason) {
+			evicted = append(evicted, key)
+		}))
+		c.Write("a", 1)
+		c.Write("b", 2)
+		_, _ = c.Read("a")
+		c.Write("c", 3)
+		if value, isCacheMiss := c.Read("a"); isCacheMiss || value != 1 || c.Contains("b") {
+			t.Fatalf("expected b to be evicted as the least recently used, got value=%d, isCacheMiss=%t", value, isCacheMiss)
+		}
+		if err := c.Resize(1); err != nil {
+			t.Fatalf("unexpected error resizing the cache: %v", err)
+		}
+		c.Purge()
+		expected := []string{"b", "c", "a"}
+		if !reflect.DeepEqual(evicted, expected) {
+			t.Fatalf("expected evictions %#v but got %#v", expected, evicted)
+		}
+		if stats := c.Stats(); stats.Hits != 2 || stats.Misses != 0 || stats.Evictions != 2 || stats.Len != 0 {
+			t.Fatalf("unexpected stats %#v", stats)
+		}
+	})
+	t.Run("registering a name twice panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected a panic for a duplicate strategy")
+			}
+		}()
+		Register(LRU, func(size int) Policy[any, any] {
+			return newLRU[any, any](size)
+		})
+	})
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"math"
+)
+
+const (
+	// s3FIFOSmallRatio is the share of the capacity given to the small queue, as recommended by the paper.
+	s3FIFOSmallRatio = 0.1
+	// s3FIFOMaxFreq caps the access counters, which only need two bits.
+	s3FIFOMaxFreq = 3
+)
+
+// s3FIFO implements Policy
+// It follows S3-FIFO as described in "FIFO queues are all you need for cache
+// eviction" by Juncheng Yang, Yazhuo Zhang, Ziyue Qiu, Yao Yue and Rashmi Vinayak.
+// New keys go through a small FIFO queue which quickly evicts the ones
+// requested only once, the others move to the main FIFO queue.
+type s3FIFO[K comparable, V any] struct {
+	evictNotifier[K, V]
+	small     *lru[K, *s3FIFOEntry[V]]
+	main      *lru[K, *s3FIFOEntry[V]]
+	ghost     *lru[K, struct{}]
+	c         int
+	smallSize int
+}
+
+type s3FIFOEntry[V any] struct {
+	value V
+	freq  int
+}
+
+func newS3FIFO[K comparable, V any](size int) *s3FIFO[K, V] {
+	c := &s3FIFO[K, V]{
+		small: newLRU[K, *s3FIFOEntry[V]](math.MaxInt),
+		main:  newLRU[K, *s3FIFOEntry[V]](math.MaxInt),
+		ghost: newLRU[K, struct{}](0),
+	}
+	c.setSize(size)
+	return c
+}
+
+func (c *s3FIFO[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	e := c.find(key)
+	if e == nil {
+		return value, true
+	}
+	e.freq = min(e.freq+1, s3FIFOMaxFreq)
+	return e.value, false
+}
+
+func (c *s3FIFO[K, V]) Write(key K, value V) {
+	if e := c.find(key); e != nil {
+		e.value = value
+		e.freq = min(e.freq+1, s3FIFOMaxFreq)
+		return
+	}
+	if c.Len() >= c.c {
+		c.evict()
+	}
+	if c.ghost.remove(key) != nil {
+		_ = c.main.insert(key, &s3FIFOEntry[V]{value: value})
+		return
+	}
+	_ = c.small.insert(key, &s3FIFOEntry[V]{value: value})
+}
+
+func (c *s3FIFO[K, V]) Peek(key K) (value V, found bool) {
+	if e := c.find(key); e != nil {
+		return e.value, true
+	}
+	return value, false
+}
+
+func (c *s3FIFO[K, V]) Delete(key K) bool {
+	if c.ghost.Delete(key) {
+		return false
+	}
+	return c.small.Delete(key) || c.main.Delete(key)
+}
+
+func (c *s3FIFO[K, V]) Len() int {
+	return c.small.Len() + c.main.Len()
+}
+
+func (c *s3FIFO[K, V]) Capacity() int {
+	return c.c
+}
+
+func (c *s3FIFO[K, V]) Keys() []K {
+	return append(c.main.Keys(), c.small.Keys()...)
+}
+
+func (c *s3FIFO[K, V]) Purge() {
+	c.small.Purge()
+	c.main.Purge()
+	c.ghost.Purge()
+}
+
+// Resize scales the small and ghost queues to the new size. When shrinking,
+// keys are evicted as usual until they fit.
+func (c *s3FIFO[K, V]) Resize(size int) {
+	c.setSize(size)
+	for c.Len() > c.c {
+		c.evict()
+	}
+	_ = c.ghost.resize(c.ghost.size)
+}
+
+// evict drops a key from the small queue while it holds more than its share
+// of the capacity, otherwise from the main queue. Keys requested during
+// their stay in the small queue move to the main queue instead, keys
+// requested during their stay in the main queue get another turn.
+func (c *s3FIFO[K, V]) evict() {
+	for {
+		if c.small.Len() >= c.smallSize || c.main.Len() == 0 {
+			node := c.small.remove(c.small.last.key)
+			if node.value.freq > 0 {
+				node.value.freq = 0
+				_ = c.main.insert(node.key, node.value)
+				continue
+			}
+			_, _ = c.ghost.write(node.key, struct{}{})
+			c.notifyEvict(node.key, node.value.value)
+			return
+		}
+		node := c.main.remove(c.main.last.key)
+		if node.value.freq > 0 {
+			node.value.freq--
+			_ = c.main.insert(node.key, node.value)
+			continue
+		}
+		c.notifyEvict(node.key, node.value.value)
+		return
+	}
+}
+
+// find returns the entry of a key in the small or the main queue, or nil.
+func (c *s3FIFO[K, V]) find(key K) *s3FIFOEntry[V] {
+	if node, found := c.small.hash[key]; found {
+		return node.value
+	}
+	if node, found := c.main.hash[key]; found {
+		return node.value
+	}
+	return nil
+}
+
+// setSize changes the capacity along with the size of the small queue,
+// which is at least one. The ghost queue remembers as many keys as the main
+// queue holds.
+func (c *s3FIFO[K, V]) setSize(size int) {
+	c.c = size
+	c.smallSize = max(1, int(math.Round(float64(size)*s3FIFOSmallRatio)))
+	c.ghost.size = max(1, size-c.smallSize)
+}
+
+type s3FIFOState[K comparable] struct {
+	small, main, ghost []K // keys from newest to oldest
+}
+
+func (c *s3FIFO[K, V]) state() s3FIFOState[K] {
+	return s3FIFOState[K]{
+		small: c.small.Keys(),
+		main:  c.main.Keys(),
+		ghost: c.ghost.Keys(),
+	}
+}
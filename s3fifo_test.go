@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestS3FIFO(t *testing.T) {
+	t.Run("keys requested in the small queue move to the main queue", func(t *testing.T) {
+		type step struct {
+			write    bool
+			key      int
+			expected s3FIFOState[int]
+			evicted  []int // keys evicted by the step
+		}
+		steps := []step{ // size=4, the small queue holds 1 key and the ghost queue 3
+			{write: true, key: 1, expected: s3FIFOState[int]{small: []int{1}}},
+			{write: true, key: 2, expected: s3FIFOState[int]{small: []int{2, 1}}},
+			{write: true, key: 3, expected: s3FIFOState[int]{small: []int{3, 2, 1}}},
+			{write: true, key: 4, expected: s3FIFOState[int]{small: []int{4, 3, 2, 1}}},
+			{key: 1, expected: s3FIFOState[int]{small: []int{4, 3, 2, 1}}},
+			// 1 was requested, it moves to the main queue and 2 is evicted instead.
+			{write: true, key: 5, evicted: []int{2},
+				expected: s3FIFOState[int]{small: []int{5, 4, 3}, main: []int{1}, ghost: []int{2}}},
+			{write: true, key: 6, evicted: []int{3},
+				expected: s3FIFOState[int]{small: []int{6, 5, 4}, main: []int{1}, ghost: []int{3, 2}}},
+			// 2 is remembered by the ghost queue, so it goes straight to the main queue.
+			{write: true, key: 2, evicted: []int{4},
+				expected: s3FIFOState[int]{small: []int{6, 5}, main: []int{2, 1}, ghost: []int{4, 3}}},
+			{key: 1, expected: s3FIFOState[int]{small: []int{6, 5}, main: []int{2, 1}, ghost: []int{4, 3}}},
+			{write: true, key: 7, evicted: []int{5},
+				expected: s3FIFOState[int]{small: []int{7, 6}, main: []int{2, 1}, ghost: []int{5, 4, 3}}},
+			{key: 6, expected: s3FIFOState[int]{small: []int{7, 6}, main: []int{2, 1}, ghost: []int{5, 4, 3}}},
+			{key: 7, expected: s3FIFOState[int]{small: []int{7, 6}, main: []int{2, 1}, ghost: []int{5, 4, 3}}},
+			// The small queue empties into the main queue, which gives 1 another
+			// turn and evicts 2, without remembering it.
+			{write: true, key: 8, evicted: []int{2},
+				expected: s3FIFOState[int]{small: []int{8}, main: []int{1, 7, 6}, ghost: []int{5, 4, 3}}},
+		}
+		c := newS3FIFO[int, int](4)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i, step := range steps {
+			evicted = evicted[:0]
+			if step.write {
+				c.Write(step.key, step.key*10)
+			} else if value, isCacheMiss := c.Read(step.key); isCacheMiss || value != step.key*10 {
+				t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+			}
+			if state := c.state(); !equalStates(state, step.expected) {
+				t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+			}
+			if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+				t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+			}
+		}
+	})
+	t.Run("deleted keys are forgotten by the ghost queue", func(t *testing.T) {
+		c := newS3FIFO[int, int](2)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30) // 1 is in the ghost queue
+		if c.Delete(1) || !c.Delete(2) || c.Delete(2) {
+			t.Fatalf("unexpected deletes, state %#v", c.state())
+		}
+		c.Write(1, 10)
+		if state := c.state(); !equalStates(state, s3FIFOState[int]{small: []int{1, 3}}) {
+			t.Fatalf("expected key 1 to be written in the small queue again but got %#v", state)
+		}
+	})
+	t.Run("hit rate beats lru on a skewed workload with scans", func(t *testing.T) {
+		expectHigherHitRate(t, skewedTrace(8, 10000, 100000, true), 200, S3FIFO, LRU)
+	})
+}
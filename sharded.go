@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"hash/maphash"
+	"time"
+)
+
+// newSharded splits size between the number of shards in the settings.
+// Each shard gets its own lock so it's always safe for concurrent use.
+// Each shard needs at least shardMin slots, the smallest size supported by
+// the algorithm, so the number of shards is capped accordingly.
+func newSharded[K comparable, V any](algorithm string, size, shardMin int, s *settings) *sharded[K, V] {
+	numShards := max(1, min(s.shards, size/shardMin))
+	shards := make([]Cache[K, V], numShards)
+	for i := range shards {
+		shardSize := splitShards(size, numShards, i)
+		shards[i] = newSynchronized[K, V](newFrontend[K, V](algorithm, shardSize, shardMin, s))
+	}
+	return &sharded[K, V]{
+		seed:      maphash.MakeSeed(),
+		shards:    shards,
+		algorithm: algorithm,
+		shardMin:  shardMin,
+	}
+}
+
+// sharded implements Cache
+// It hashes keys across independent instances of the same strategy so that
+// goroutines working on different keys don't compete for the same lock.
+type sharded[K comparable, V any] struct {
+	seed      maphash.Seed
+	shards    []Cache[K, V]
+	algorithm string
+	shardMin  int // smallest size of a shard
+}
+
+func (s *sharded[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	return s.shard(key).Read(key)
+}
+
+func (s *sharded[K, V]) Write(key K, value V) {
+	s.shard(key).Write(key, value)
+}
+
+func (s *sharded[K, V]) WriteWithTTL(key K, value V, ttl time.Duration) {
+	s.shard(key).WriteWithTTL(key, value, ttl)
+}
+
+// WriteWithCost routes the entry, with its cost and size, to the shard which owns the key.
+func (s *sharded[K, V]) WriteWithCost(key K, value V, cost float64, size int) {
+	s.shard(key).WriteWithCost(key, value, cost, size)
+}
+
+// Stats adds up the stats of all shards. Segments are added up by name,
+// so for ARC "p" is the sum of the target sizes of all shards.
+func (s *sharded[K, V]) Stats() Stats {
+	total := Stats{}
+	for _, shard := range s.shards {
+		stats := shard.Stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Writes += stats.Writes
+		total.Updates += stats.Updates
+		total.Evictions += stats.Evictions
+		total.Len += stats.Len
+		for name, size := range stats.Segments {
+			if total.Segments == nil {
+				total.Segments = make(map[string]int)
+			}
+			total.Segments[name] += size
+		}
+	}
+	return total
+}
+
+func (s *sharded[K, V]) ResetStats() {
+	for _, shard := range s.shards {
+		shard.ResetStats()
+	}
+}
+
+func (s *sharded[K, V]) Delete(key K) bool {
+	return s.shard(key).Delete(key)
+}
+
+func (s *sharded[K, V]) Peek(key K) (value V, found bool) {
+	return s.shard(key).Peek(key)
+}
+
+func (s *sharded[K, V]) Contains(key K) bool {
+	return s.shard(key).Contains(key)
+}
+
+// Len adds up the lengths of all shards, without locking all of them at once.
+func (s *sharded[K, V]) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+func (s *sharded[K, V]) Capacity() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Capacity()
+	}
+	return total
+}
+
+func (s *sharded[K, V]) Purge() {
+	for _, shard := range s.shards {
+		shard.Purge()
+	}
+}
+
+// Resize splits the new size between the existing shards,
+// so it can't be smaller than the minimum size of all shards together.
+func (s *sharded[K, V]) Resize(size int) error {
+	if smallest := len(s.shards) * s.shardMin; size < smallest {
+		return &InvalidSizeError{Algorithm: s.algorithm, Size: size, Min: smallest}
+	}
+	for i, shard := range s.shards {
+		if err := shard.Resize(splitShards(size, len(s.shards), i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shard returns the instance responsible for the given key.
+func (s *sharded[K, V]) shard(key K) Cache[K, V] {
+	hash := maphash.Comparable(s.seed, key)
+	return s.shards[hash%uint64(len(s.shards))]
+}
+
+// splitShards returns the size of the i-th out of numShards shards.
+func splitShards(size, numShards, i int) int {
+	shardSize := size / numShards
+	if i < size%numShards {
+		shardSize++
+	}
+	return shardSize
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	t.Run("capacity is split between shards", func(t *testing.T) {
+		c := newSharded[int, int](LRU, 10, 1, newSettings([]Option{WithShards(4)}))
+		if len(c.shards) != 4 {
+			t.Fatalf("expected 4 shards but got %d", len(c.shards))
+		}
+		total := 0
+		for idx, shard := range c.shards {
+			size := shard.(*synchronized[int, int]).cache.(*cache[int, int]).policy.(*lru[int, *entry[int, int]]).size
+			if size != 2 && size != 3 {
+				t.Fatalf("expected shard #%d to hold 2 or 3 entries but it holds %d", idx, size)
+			}
+			total += size
+		}
+		if total != 10 {
+			t.Fatalf("expected shard sizes to add up to 10 but got %d", total)
+		}
+	})
+	t.Run("number of shards is capped by the cache size", func(t *testing.T) {
+		c := newSharded[int, int](LRU, 3, 1, newSettings([]Option{WithShards(8)}))
+		if len(c.shards) != 3 {
+			t.Fatalf("expected 3 shards but got %d", len(c.shards))
+		}
+		c = newSharded[int, int](SLRU, 7, 2, newSettings([]Option{WithShards(8)}))
+		if len(c.shards) != 3 {
+			t.Fatalf("expected 3 shards with at least 2 slots each but got %d", len(c.shards))
+		}
+	})
+	t.Run("keys are routed to the same shard", func(t *testing.T) {
+		c := Factory[string, int](ARC, 64, WithShards(8))
+		for i, key := range []string{"a", "b", "c", "d", "e", "f"} {
+			c.Write(key, i)
+		}
+		for i, key := range []string{"a", "b", "c", "d", "e", "f"} {
+			if value, isCacheMiss := c.Read(key); isCacheMiss || value != i {
+				t.Fatalf("expected to read key %s but got value=%d, isCacheMiss=%t", key, value, isCacheMiss)
+			}
+		}
+	})
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" shards support concurrent reads and writes", func(t *testing.T) {
+			var (
+				c  = Factory[int, int](algorithm, 256, WithShards(8))
+				wg sync.WaitGroup
+			)
+			for w := 0; w < 16; w++ {
+				wg.Add(1)
+				go func(offset int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := (offset + i) % 512
+						c.Write(key, key)
+						_, _ = c.Read(key)
+					}
+				}(w * 31)
+			}
+			wg.Wait()
+		})
+	}
+	t.Run("resize splits the new size between shards", func(t *testing.T) {
+		c := Factory[int, int](LRU, 8, WithShards(4))
+		if err := c.Resize(10); err != nil || c.Capacity() != 10 {
+			t.Fatalf("expected a capacity of 10 but got %d, error %v", c.Capacity(), err)
+		}
+		var invalid *InvalidSizeError
+		if err := c.Resize(3); !errors.As(err, &invalid) || invalid.Min != 4 || c.Capacity() != 10 {
+			t.Fatalf("expected resizing below the number of shards to fail but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"math"
+)
+
+func newSIEVE[K comparable, V any](size int) *sieve[K, V] {
+	return &sieve[K, V]{
+		queue: newLRU[K, *sieveEntry[V]](math.MaxInt),
+		size:  size,
+	}
+}
+
+// sieve implements Policy
+// It follows SIEVE as described in "SIEVE is Simpler than LRU: an Efficient
+// Turn-Key Eviction Algorithm for Web Caches" by Yazhuo Zhang, Juncheng Yang,
+// Yao Yue, Ymir Vigfusson and K. V. Rashmi.
+//
+// SIEVE keeps the keys in a FIFO queue with a visited bit each. A hit only
+// sets the bit, keys don't move. To make room, the hand moves from the oldest
+// keys towards the newest ones, clearing the bits it finds set, and evicts
+// the first key without one. Unlike CLOCK, the surviving keys stay where they
+// are, so new keys which are not requested again are evicted quickly.
+type sieve[K comparable, V any] struct {
+	evictNotifier[K, V]
+	queue *lru[K, *sieveEntry[V]] // the head is the newest key
+	hand  *lruNode[K, *sieveEntry[V]]
+	size  int
+}
+
+type sieveEntry[V any] struct {
+	value   V
+	visited bool
+}
+
+func (c *sieve[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	node, found := c.queue.hash[key]
+	if !found {
+		return value, true
+	}
+	node.value.visited = true
+	return node.value.value, false
+}
+
+func (c *sieve[K, V]) Write(key K, value V) {
+	if node, found := c.queue.hash[key]; found {
+		node.value.value = value
+		node.value.visited = true
+		return
+	}
+	if c.queue.Len() >= c.size {
+		c.evict()
+	}
+	_ = c.queue.insert(key, &sieveEntry[V]{value: value})
+}
+
+func (c *sieve[K, V]) Peek(key K) (value V, found bool) {
+	if node, found := c.queue.hash[key]; found {
+		return node.value.value, true
+	}
+	return value, false
+}
+
+func (c *sieve[K, V]) Delete(key K) bool {
+	node, found := c.queue.hash[key]
+	if !found {
+		return false
+	}
+	c.remove(node)
+	return true
+}
+
+func (c *sieve[K, V]) Len() int {
+	return c.queue.Len()
+}
+
+func (c *sieve[K, V]) Capacity() int {
+	return c.size
+}
+
+// Keys returns the keys from the newest to the oldest.
+func (c *sieve[K, V]) Keys() []K {
+	return c.queue.Keys()
+}
+
+func (c *sieve[K, V]) Purge() {
+	c.queue.Purge()
+	c.hand = nil
+}
+
+func (c *sieve[K, V]) Resize(size int) {
+	c.size = size
+	for c.queue.Len() > c.size {
+		c.evict()
+	}
+}
+
+// evict moves the hand towards the newest keys, starting over from the
+// oldest one when it reaches the head, until it finds a key which was not
+// visited since its last turn. The hand stays where the evicted key was.
+func (c *sieve[K, V]) evict() {
+	node := c.hand
+	if node == nil {
+		node = c.queue.last
+	}
+	for node.value.visited {
+		node.value.visited = false
+		if node = node.previous; node == nil {
+			node = c.queue.last
+		}
+	}
+	c.hand = node.previous
+	_ = c.queue.remove(node.key)
+	c.notifyEvict(node.key, node.value.value)
+}
+
+// remove takes the key out of the queue, moving the hand to the next newer key if it points at it.
+func (c *sieve[K, V]) remove(node *lruNode[K, *sieveEntry[V]]) {
+	if c.hand == node {
+		c.hand = node.previous
+	}
+	_ = c.queue.remove(node.key)
+}
+
+type sieveState[K comparable] struct {
+	keys    []K // from the newest to the oldest
+	visited []K
+	hand    *K // nil when the hand starts over from the oldest key
+}
+
+func (c *sieve[K, V]) state() sieveState[K] {
+	s := sieveState[K]{keys: c.queue.Keys()}
+	for node := c.queue.head; node != nil; node = node.next {
+		if node.value.visited {
+			s.visited = append(s.visited, node.key)
+		}
+	}
+	if c.hand != nil {
+		s.hand = &c.hand.key
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSIEVE(t *testing.T) {
+	t.Run("the hand sifts visited keys and stays where it evicted", func(t *testing.T) {
+		c := newSIEVE[int, int](3)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		check := func(keys, visited []int, hand int) {
+			t.Helper()
+			state := c.state()
+			if !reflect.DeepEqual(state.keys, keys) || len(state.visited) != len(visited) ||
+				(len(visited) > 0 && !reflect.DeepEqual(state.visited, visited)) {
+				t.Fatalf("expected keys %#v, visited %#v but got %#v", keys, visited, state)
+			}
+			if (hand == 0) != (state.hand == nil) || (state.hand != nil && *state.hand != hand) {
+				t.Fatalf("expected the hand at %d but got %#v", hand, state.hand)
+			}
+		}
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		check([]int{3, 2, 1}, []int{1}, 0)
+		c.Write(4, 40) // 1 survives in place, 2 is evicted
+		check([]int{4, 3, 1}, nil, 3)
+		_, _ = c.Read(1)
+		_, _ = c.Read(4)
+		c.Write(5, 50) // the hand evicts 3 without looking at the oldest key
+		check([]int{5, 4, 1}, []int{4, 1}, 4)
+		c.Write(6, 60) // the hand clears 4 and evicts 5, then starts over
+		check([]int{6, 4, 1}, []int{1}, 0)
+		c.Write(7, 70)
+		check([]int{7, 6, 1}, nil, 6)
+		if !reflect.DeepEqual(evicted, []int{2, 3, 5, 4}) {
+			t.Fatalf("unexpected evictions %#v", evicted)
+		}
+		if !c.Delete(6) || c.Delete(6) {
+			t.Fatal("expected to delete key 6 once")
+		}
+		check([]int{7, 1}, nil, 7)
+	})
+	t.Run("hit rate beats lru on a skewed workload", func(t *testing.T) {
+		expectHigherHitRate(t, skewedTrace(8, 10000, 100000, false), 200, SIEVE, LRU)
+	})
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"math/bits"
+)
+
+const (
+	// sketchDepth is the number of rows of counters, each indexed by a different hash.
+	sketchDepth = 4
+	// sketchMaxCount caps the counters, like the 4 bit counters of Caffeine.
+	sketchMaxCount = 15
+	// sketchWidthPerEntry sets the number of counters in each row, relative
+	// to the capacity of the cache. Wider rows mean fewer collisions.
+	sketchWidthPerEntry = 4
+	// sketchSamplesPerEntry sets how many increments trigger the aging of the
+	// sketch, relative to the capacity of the cache.
+	sketchSamplesPerEntry = 10
+	// doorkeeperBitsPerSample sizes the doorkeeper so it holds all the keys
+	// requested between two agings with few false positives.
+	doorkeeperBitsPerSample = 8
+)
+
+// countMinSketch estimates how often keys were requested, in a fixed amount of
+// memory. Estimates can be too large because of hash collisions but never too
+// small. Keys are recorded in the doorkeeper first, so the counters are only
+// used by keys requested at least twice. After a number of increments
+// proportional to the capacity, all counters are halved and the doorkeeper is
+// cleared, so that keys which are not popular anymore lose their advantage.
+type countMinSketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint64 // the width of the rows is a power of two
+	doorkeeper *bloomFilter
+	additions  int
+	sampleSize int
+}
+
+func newCountMinSketch(capacity int) *countMinSketch {
+	width := nextPowerOfTwo(max(sketchWidthPerEntry*capacity, 64))
+	s := &countMinSketch{
+		mask:       uint64(width - 1),
+		sampleSize: sketchSamplesPerEntry * capacity,
+	}
+	s.doorkeeper = newBloomFilter(doorkeeperBitsPerSample * s.sampleSize)
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// increment records a request for the key with the given hash.
+func (s *countMinSketch) increment(hash uint64) {
+	if !s.doorkeeper.contains(hash) {
+		s.doorkeeper.add(hash)
+	} else {
+		// Conservative update: only the smallest counters are incremented,
+		// the others already overestimate the key because of collisions.
+		smallest := s.count(hash)
+		for i := range s.rows {
+			counter := &s.rows[i][s.index(hash, i)]
+			if *counter == smallest && *counter < sketchMaxCount {
+				*counter++
+			}
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.age()
+	}
+}
+
+// estimate returns the approximate number of requests for the key with the given hash.
+func (s *countMinSketch) estimate(hash uint64) int {
+	count := int(s.count(hash))
+	if s.doorkeeper.contains(hash) {
+		count++
+	}
+	return count
+}
+
+// resize changes the number of increments between two agings to follow the
+// capacity of the cache. The counters are kept, with their collisions.
+func (s *countMinSketch) resize(capacity int) {
+	s.sampleSize = sketchSamplesPerEntry * capacity
+}
+
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.doorkeeper.reset()
+	s.additions = 0
+}
+
+// age halves all the counters and clears the doorkeeper.
+func (s *countMinSketch) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.doorkeeper.reset()
+	s.additions /= 2
+}
+
+// count returns the smallest of the counters of the key.
+func (s *countMinSketch) count(hash uint64) uint8 {
+	smallest := uint8(sketchMaxCount)
+	for i := range s.rows {
+		if counter := s.rows[i][s.index(hash, i)]; counter < smallest {
+			smallest = counter
+		}
+	}
+	return smallest
+}
+
+// index derives the position of the key in the i-th row by mixing its hash
+// with a different seed for each row.
+func (s *countMinSketch) index(hash uint64, i int) uint64 {
+	return rehash(hash, i) & s.mask
+}
+
+// bloomFilter tells whether a key was added, with a small rate of false positives.
+type bloomFilter struct {
+	bits []uint64
+	mask uint64 // the number of bits is a power of two
+}
+
+// bloomHashes is the number of bits set for each key.
+const bloomHashes = 3
+
+func newBloomFilter(numBits int) *bloomFilter {
+	numBits = nextPowerOfTwo(max(numBits, 64))
+	return &bloomFilter{
+		bits: make([]uint64, numBits/64),
+		mask: uint64(numBits - 1),
+	}
+}
+
+func (f *bloomFilter) add(hash uint64) {
+	for i := 0; i < bloomHashes; i++ {
+		bit := f.index(hash, i)
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *bloomFilter) contains(hash uint64) bool {
+	for i := 0; i < bloomHashes; i++ {
+		bit := f.index(hash, i)
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) reset() {
+	clear(f.bits)
+}
+
+// index uses different seeds than the sketch, so that the doorkeeper
+// and the counters don't collide for the same keys.
+func (f *bloomFilter) index(hash uint64, i int) uint64 {
+	return rehash(hash, sketchDepth+i) & f.mask
+}
+
+// seeds are odd constants used to derive independent hashes from a single one.
+var seeds = [sketchDepth + bloomHashes]uint64{
+	0x97cb3127, 0xab73b37f, 0x8f21f7d5, 0xc2b2ae3d,
+	0x27d4eb2f, 0x165667b1, 0x85ebca6b,
+}
+
+// rehash returns the i-th hash derived from hash.
+func rehash(hash uint64, i int) uint64 {
+	hash = (hash + seeds[i]) * seeds[i]
+	return hash + hash>>32
+}
+
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	t.Run("keys requested once are only recorded by the doorkeeper", func(t *testing.T) {
+		s := newCountMinSketch(100)
+		s.increment(42)
+		if s.count(42) != 0 || !s.doorkeeper.contains(42) || s.estimate(42) != 1 {
+			t.Fatalf("unexpected count %d and estimate %d after one request", s.count(42), s.estimate(42))
+		}
+		s.increment(42)
+		if s.count(42) != 1 || s.estimate(42) != 2 {
+			t.Fatalf("unexpected count %d and estimate %d after two requests", s.count(42), s.estimate(42))
+		}
+	})
+	t.Run("estimates are never smaller than the number of requests", func(t *testing.T) {
+		var (
+			rnd      = rand.New(rand.NewSource(3))
+			s        = newCountMinSketch(1000)
+			requests = map[uint64]int{}
+		)
+		for i := 0; i < 5000; i++ {
+			hash := rnd.Uint64() % 500
+			s.increment(hash)
+			requests[hash]++
+		}
+		for hash, count := range requests {
+			if estimate := s.estimate(hash); estimate < min(count, sketchMaxCount+1) {
+				t.Fatalf("key %d was requested %d times but the estimate is %d", hash, count, estimate)
+			}
+		}
+	})
+	t.Run("counters are capped", func(t *testing.T) {
+		s := newCountMinSketch(100)
+		for i := 0; i < 100; i++ {
+			s.increment(7)
+		}
+		if s.estimate(7) != sketchMaxCount+1 {
+			t.Fatalf("expected the estimate to be capped but got %d", s.estimate(7))
+		}
+	})
+	t.Run("aging halves the counters and clears the doorkeeper", func(t *testing.T) {
+		s := newCountMinSketch(1) // ages every 10 increments
+		for i := 0; i < 9; i++ {
+			s.increment(7)
+		}
+		if s.estimate(7) != 9 || s.additions != 9 {
+			t.Fatalf("unexpected estimate %d before aging", s.estimate(7))
+		}
+		s.increment(7)
+		if s.estimate(7) != 4 || s.doorkeeper.contains(7) || s.additions != 5 {
+			t.Fatalf("unexpected estimate %d after aging", s.estimate(7))
+		}
+	})
+	t.Run("bloom filter has no false negatives", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(4))
+		f := newBloomFilter(1024)
+		added := []uint64{}
+		for i := 0; i < 100; i++ {
+			hash := rnd.Uint64()
+			f.add(hash)
+			added = append(added, hash)
+		}
+		for _, hash := range added {
+			if !f.contains(hash) {
+				t.Fatalf("expected %d to be in the filter", hash)
+			}
+		}
+		f.reset()
+		if f.contains(added[0]) {
+			t.Fatal("expected the filter to be empty after a reset")
+		}
+	})
+}
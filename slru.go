@@ -1,19 +1,27 @@
 package cache
 
-type slru struct {
-	protected *lru
-	probation *lru
+import (
+	"math"
+)
+
+// slru implements Policy
+type slru[K comparable, V any] struct {
+	evictNotifier[K, V]
+	protected *lru[K, V]
+	probation *lru[K, V]
+	ratio     float64 // share of the capacity given to protected
 }
 
-func newSLRU(size int) *slru {
-	first, second := (size+1)/2, size/2
-	return &slru{
-		protected: newLRU(first),
-		probation: newLRU(second),
+func newSLRU[K comparable, V any](size int, ratio float64) *slru[K, V] {
+	first, second := splitSegments(size, ratio)
+	return &slru[K, V]{
+		protected: newLRU[K, V](first),
+		probation: newLRU[K, V](second),
+		ratio:     ratio,
 	}
 }
 
-func (c *slru) Read(key int) (int, bool) {
+func (c *slru[K, V]) Read(key K) (V, bool) {
 	// Search the protected section.
 	value, cacheMiss := c.protected.Read(key)
 	if !cacheMiss {
@@ -22,19 +30,16 @@ func (c *slru) Read(key int) (int, bool) {
 	// Search the probation section.
 	value, cacheMiss = c.probation.Read(key)
 	if cacheMiss {
-		return 0, true
+		return value, true
 	}
 	// Promote from probation to protected taking care of any
 	// evicted overflow from protected.
 	_ = c.probation.remove(key)
-	_, evicted := c.protected.write(key, value)
-	if evicted != nil {
-		c.probation.Write(evicted.key, evicted.value)
-	}
+	c.promote(key, value)
 	return value, false
 }
 
-func (c *slru) Write(key, value int) {
+func (c *slru[K, V]) Write(key K, value V) {
 	// Key is in protected so we update the value.
 	node := c.protected.read(key)
 	if node != nil {
@@ -44,14 +49,73 @@ func (c *slru) Write(key, value int) {
 	// Key is not in probation so we write the new page in probation.
 	node = c.probation.read(key)
 	if node == nil {
-		c.probation.Write(key, value)
+		c.demote(key, value)
 		return
 	}
 	// Key is in probabation. We move the key to protected, update the
 	// value and handle any overflow from protected.
 	_ = c.probation.remove(key)
-	_, evicted := c.protected.write(key, value)
-	if evicted != nil {
-		c.probation.Write(evicted.key, evicted.value)
+	c.promote(key, value)
+}
+
+func (c *slru[K, V]) Peek(key K) (value V, found bool) {
+	if value, found = c.protected.Peek(key); found {
+		return value, true
+	}
+	return c.probation.Peek(key)
+}
+
+func (c *slru[K, V]) Delete(key K) bool {
+	return c.protected.Delete(key) || c.probation.Delete(key)
+}
+
+func (c *slru[K, V]) Len() int {
+	return c.protected.Len() + c.probation.Len()
+}
+
+func (c *slru[K, V]) Capacity() int {
+	return c.protected.Capacity() + c.probation.Capacity()
+}
+
+func (c *slru[K, V]) Keys() []K {
+	return append(c.protected.Keys(), c.probation.Keys()...)
+}
+
+func (c *slru[K, V]) Purge() {
+	c.protected.Purge()
+	c.probation.Purge()
+}
+
+// Resize splits the new size between the segments. When shrinking,
+// probation evicts whatever doesn't fit in anymore, then keys overflowing
+// from protected are moved into probation, evicting more keys from it.
+func (c *slru[K, V]) Resize(size int) {
+	first, second := splitSegments(size, c.ratio)
+	for _, node := range c.probation.resize(second) {
+		c.notifyEvict(node.key, node.value)
 	}
+	for _, node := range c.protected.resize(first) {
+		c.demote(node.key, node.value)
+	}
+}
+
+// promote writes the key in protected and moves its overflow back into probation.
+func (c *slru[K, V]) promote(key K, value V) {
+	if _, evicted := c.protected.write(key, value); evicted != nil {
+		c.demote(evicted.key, evicted.value)
+	}
+}
+
+// demote writes the key in probation. Keys falling out of probation leave the cache.
+func (c *slru[K, V]) demote(key K, value V) {
+	if _, evicted := c.probation.write(key, value); evicted != nil {
+		c.notifyEvict(evicted.key, evicted.value)
+	}
+}
+
+// splitSegments gives ratio of size to the first segment and the rest to the second.
+// Halves are rounded in favour of the first segment.
+func splitSegments(size int, ratio float64) (first, second int) {
+	first = int(math.Round(float64(size) * ratio))
+	return first, size - first
 }
@@ -0,0 +1,137 @@
+package cache
+
+// slruN implements Policy
+// It generalises slru to any number of lru segments. New keys enter the
+// lowest segment and every hit moves a key one segment up. The overflow of
+// each segment cascades into the one below it, until it falls out of the
+// lowest segment and leaves the cache. With two segments it behaves like slru.
+type slruN[K comparable, V any] struct {
+	evictNotifier[K, V]
+	segments []*lru[K, V] // from the lowest, probation, to the highest
+}
+
+func newSLRUN[K comparable, V any](size, numSegments int) *slruN[K, V] {
+	c := &slruN[K, V]{
+		segments: make([]*lru[K, V], numSegments),
+	}
+	for level := range c.segments {
+		c.segments[level] = newLRU[K, V](c.split(size, level))
+	}
+	return c
+}
+
+func (c *slruN[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	level := c.find(key)
+	if level < 0 {
+		return value, true
+	}
+	node := c.segments[level].hash[key]
+	c.promote(level, node.key, node.value)
+	return node.value, false
+}
+
+// Write updates the value of a key which is already in the cache and
+// promotes it, as if it was read. New keys enter the lowest segment.
+func (c *slruN[K, V]) Write(key K, value V) {
+	level := c.find(key)
+	if level < 0 {
+		c.insert(0, key, value)
+		return
+	}
+	c.promote(level, key, value)
+}
+
+func (c *slruN[K, V]) Peek(key K) (value V, found bool) {
+	if level := c.find(key); level >= 0 {
+		return c.segments[level].hash[key].value, true
+	}
+	return value, false
+}
+
+func (c *slruN[K, V]) Delete(key K) bool {
+	level := c.find(key)
+	return level >= 0 && c.segments[level].Delete(key)
+}
+
+func (c *slruN[K, V]) Len() int {
+	length := 0
+	for _, segment := range c.segments {
+		length += segment.Len()
+	}
+	return length
+}
+
+func (c *slruN[K, V]) Capacity() int {
+	capacity := 0
+	for _, segment := range c.segments {
+		capacity += segment.Capacity()
+	}
+	return capacity
+}
+
+// Keys lists the keys from the highest segment to the lowest.
+func (c *slruN[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	for level := len(c.segments) - 1; level >= 0; level-- {
+		keys = append(keys, c.segments[level].Keys()...)
+	}
+	return keys
+}
+
+func (c *slruN[K, V]) Purge() {
+	for _, segment := range c.segments {
+		segment.Purge()
+	}
+}
+
+// Resize splits the new size between the segments. When shrinking, segments
+// are resized from the lowest up, so the overflow of each segment cascades
+// into the segments below, which already have their new size.
+func (c *slruN[K, V]) Resize(size int) {
+	for level, segment := range c.segments {
+		for _, node := range segment.resize(c.split(size, level)) {
+			c.insert(level-1, node.key, node.value)
+		}
+	}
+}
+
+// find returns the level of the segment holding the key or -1 if it's not in the cache.
+func (c *slruN[K, V]) find(key K) int {
+	for level, segment := range c.segments {
+		if _, present := segment.hash[key]; present {
+			return level
+		}
+	}
+	return -1
+}
+
+// promote moves a key found at the given level to the head of the segment
+// above it. Keys in the highest segment move to the head of their segment.
+func (c *slruN[K, V]) promote(level int, key K, value V) {
+	if level == len(c.segments)-1 {
+		c.segments[level].read(key).value = value
+		return
+	}
+	_ = c.segments[level].remove(key)
+	c.insert(level+1, key, value)
+}
+
+// insert writes the key at the head of the segment on the given level,
+// then cascades the overflow down. Keys falling below the lowest segment
+// leave the cache.
+func (c *slruN[K, V]) insert(level int, key K, value V) {
+	for ; level >= 0; level-- {
+		_, evicted := c.segments[level].write(key, value)
+		if evicted == nil {
+			return
+		}
+		key, value = evicted.key, evicted.value
+	}
+	c.notifyEvict(key, value)
+}
+
+// split returns the size of the segment on the given level.
+// Any remainder goes to the highest segments.
+func (c *slruN[K, V]) split(size, level int) int {
+	return splitShards(size, len(c.segments), len(c.segments)-1-level)
+}
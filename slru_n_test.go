@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSLRUN(t *testing.T) {
+	t.Run("hits promote keys one segment up and overflow cascades down", func(t *testing.T) {
+		c := newSLRUN[int, int](4, 4)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10)   // (_); (_); (_); (1)
+		_, _ = c.Read(1) // (_); (_); (1); (_)
+		_, _ = c.Read(1) // (_); (1); (_); (_)
+		c.Write(2, 20)   // (_); (1); (_); (2)
+		_, _ = c.Read(1) // (1); (_); (_); (2)
+		_, _ = c.Read(2) // (1); (_); (2); (_)
+		c.Write(3, 30)   // (1); (_); (2); (3)
+		c.Write(4, 40)   // (1); (_); (2); (4)
+		if keys := c.Keys(); !reflect.DeepEqual(keys, []int{1, 2, 4}) || !reflect.DeepEqual(evicted, []int{3}) {
+			t.Fatalf("unexpected keys %#v or evictions %#v", keys, evicted)
+		}
+		_, _ = c.Read(4) // (1); (_); (4); (2)
+		if keys := c.Keys(); !reflect.DeepEqual(keys, []int{1, 4, 2}) || len(evicted) != 1 {
+			t.Fatalf("expected key 2 to be demoted by key 4 but got keys %#v, evictions %#v", keys, evicted)
+		}
+		_, _ = c.Read(1) // (1); (_); (4); (2)
+		c.Write(2, 21)   // (1); (_); (2); (4)
+		if value, found := c.Peek(2); !found || value != 21 || !reflect.DeepEqual(c.Keys(), []int{1, 2, 4}) {
+			t.Fatalf("expected a write to update and promote key 2 but got keys %#v", c.Keys())
+		}
+	})
+	t.Run("segments are split evenly with the remainder going to the highest", func(t *testing.T) {
+		c := newSLRUN[int, int](10, 4)
+		sizes := []int{}
+		for _, segment := range c.segments {
+			sizes = append(sizes, segment.size)
+		}
+		if !reflect.DeepEqual(sizes, []int{2, 2, 3, 3}) || c.Capacity() != 10 {
+			t.Fatalf("unexpected segment sizes %#v", sizes)
+		}
+	})
+	t.Run("two segments behave like slru", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(7))
+		for _, size := range []int{2, 3, 8, 15} {
+			var (
+				expected    = newSLRU[int, int](size, defaultSegmentRatio)
+				actual      = newSLRUN[int, int](size, defaultSegments)
+				expEvicted  = []int{}
+				actEvicted  = []int{}
+				currentSize = size
+			)
+			expected.SetEvictHandler(func(key, value int) {
+				expEvicted = append(expEvicted, key)
+			})
+			actual.SetEvictHandler(func(key, value int) {
+				actEvicted = append(actEvicted, key)
+			})
+			for step := 0; step < 2000; step++ {
+				key := rnd.Intn(3 * size)
+				switch op := rnd.Intn(20); {
+				case op < 9:
+					expValue, expMiss := expected.Read(key)
+					actValue, actMiss := actual.Read(key)
+					if expValue != actValue || expMiss != actMiss {
+						t.Fatalf("size %d, step %d: read %d returned (%d, %t) instead of (%d, %t)",
+							size, step, key, actValue, actMiss, expValue, expMiss)
+					}
+				case op < 18:
+					expected.Write(key, step)
+					actual.Write(key, step)
+				case op < 19:
+					if expected.Delete(key) != actual.Delete(key) {
+						t.Fatalf("size %d, step %d: delete %d disagrees", size, step, key)
+					}
+				default:
+					currentSize = max(2, currentSize+rnd.Intn(5)-2)
+					expected.Resize(currentSize)
+					actual.Resize(currentSize)
+				}
+				if !reflect.DeepEqual(expected.Keys(), actual.Keys()) || !reflect.DeepEqual(expEvicted, actEvicted) {
+					t.Fatalf("size %d, step %d: keys %#v and evictions %#v instead of %#v and %#v",
+						size, step, actual.Keys(), actEvicted, expected.Keys(), expEvicted)
+				}
+			}
+		}
+	})
+}
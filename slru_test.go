@@ -1,12 +1,13 @@
 package cache
 
 import (
+	"reflect"
 	"testing"
 )
 
 func TestSLRU(t *testing.T) {
 	t.Run("empty cache state is correct", func(t *testing.T) {
-		c := newSLRU(2)
+		c := newSLRU[int, int](2, defaultSegmentRatio)
 		c.Write(1, 10)
 		if len(c.protected.hash) != 0 || len(c.probation.hash) != 1 ||
 			c.probation.hash[1].key != 1 || c.probation.hash[1].value != 10 ||
@@ -45,7 +46,7 @@ func TestSLRU(t *testing.T) {
 		}
 	})
 	t.Run("cache state after multiple evictions and promotions is correct", func(t *testing.T) {
-		c := newSLRU(4)
+		c := newSLRU[int, int](4, defaultSegmentRatio)
 		c.Write(1, 10)                // (_, 1); (_, _)
 		c.Write(2, 20)                // (1, 2); (_, _)
 		c.Write(3, 30)                // (2, 3); (_, _)
@@ -65,4 +66,37 @@ func TestSLRU(t *testing.T) {
 			t.Fatalf("unexpected cache state after a series of read/writes: %#v, %#v", c.protected, c.probation)
 		}
 	})
+	t.Run("shrinking moves the overflow from protected into probation", func(t *testing.T) {
+		c := newSLRU[int, int](6, defaultSegmentRatio)
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for i := 1; i <= 6; i++ {
+			c.Write(i, i*10)
+		}
+		for _, key := range []int{4, 5, 6} {
+			_, _ = c.Read(key)
+		}
+		c.Write(7, 70) // (6, 5, 4); (7)
+		c.Resize(4)    // (6, 5); (4, 7)
+		if len(c.protected.hash) != 2 || len(c.probation.hash) != 2 ||
+			c.protected.head.key != 6 || c.protected.last.key != 5 ||
+			c.probation.head.key != 4 || c.probation.last.key != 7 {
+			t.Fatalf("unexpected cache state after shrinking: %#v, %#v", c.protected.state(), c.probation.state())
+		}
+		if c.Capacity() != 4 || !reflect.DeepEqual(evicted, []int{1, 2, 3}) {
+			t.Fatalf("unexpected capacity %d or evictions %#v", c.Capacity(), evicted)
+		}
+	})
+	t.Run("segments are split according to the ratio", func(t *testing.T) {
+		c := newSLRU[int, int](10, 0.8)
+		if c.protected.size != 8 || c.probation.size != 2 {
+			t.Fatalf("unexpected segment sizes protected=%d, probation=%d", c.protected.size, c.probation.size)
+		}
+		c.Resize(5)
+		if c.protected.size != 4 || c.probation.size != 1 {
+			t.Fatalf("unexpected segment sizes after resize protected=%d, probation=%d", c.protected.size, c.probation.size)
+		}
+	})
 }
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// Stats is a snapshot of the counters maintained by every cache.
+type Stats struct {
+	Hits      uint64 // reads which found the key
+	Misses    uint64 // reads which did not find the key or found it expired
+	Writes    uint64 // writes of keys which were not in the cache
+	Updates   uint64 // writes of keys which were already in the cache
+	Evictions uint64 // entries evicted by the strategy or because they expired
+	Len       int    // number of entries currently in the cache
+	// Segments holds the number of entries in each internal segment of
+	// composite strategies, eg. "protected" and "probation" for SLRU.
+	// ARC also reports its ghost lists and its target size for t1 under "p".
+	Segments map[string]int
+}
+
+// HitRate returns the ratio of reads which were hits, between 0 and 1.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// segmented is implemented by the strategies made up of multiple segments.
+type segmented interface {
+	Segments() map[string]int
+}
+
+func (c *slru[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"protected": c.protected.Len(),
+		"probation": c.probation.Len(),
+	}
+}
+
+func (c *slruN[K, V]) Segments() map[string]int {
+	segments := make(map[string]int, len(c.segments))
+	for level, segment := range c.segments {
+		segments[fmt.Sprintf("segment%d", level)] = segment.Len()
+	}
+	return segments
+}
+
+func (c *wTinyLFU[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"window":    c.window.Len(),
+		"protected": c.main.protected.Len(),
+		"probation": c.main.probation.Len(),
+	}
+}
+
+func (c *twoQueue[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"a1in":  c.a1in.Len(),
+		"a1out": c.a1out.Len(),
+		"am":    c.am.Len(),
+	}
+}
+
+func (c *lirs[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"lir":          c.numLIR,
+		"hir":          c.queue.Len(),
+		"non-resident": c.nonResident.Len(),
+	}
+}
+
+func (c *clockPro[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"hot":          c.numHot,
+		"cold":         c.numCold,
+		"non-resident": c.numNonResident,
+	}
+}
+
+func (c *lfru[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"privileged":   c.privileged.Len(),
+		"unprivileged": c.unprivileged.Len(),
+	}
+}
+
+func (a *arc[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"t1": a.t1.Len(),
+		"t2": a.t2.Len(),
+		"b1": a.b1.Len(),
+		"b2": a.b2.Len(),
+		"p":  a.p,
+	}
+}
+
+func (c *car[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"t1": c.t1.len(),
+		"t2": c.t2.len(),
+		"b1": c.b1.Len(),
+		"b2": c.b2.Len(),
+		"p":  c.p,
+	}
+}
+
+func (c *s3FIFO[K, V]) Segments() map[string]int {
+	return map[string]int{
+		"small": c.small.Len(),
+		"main":  c.main.Len(),
+		"ghost": c.ghost.Len(),
+	}
+}
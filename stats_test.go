@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("counters are maintained for reads and writes", func(t *testing.T) {
+		c := Factory[int, int](LRU, 2)
+		c.Write(1, 10)
+		c.Write(1, 11)
+		c.Write(2, 20)
+		c.Write(3, 30)
+		_, _ = c.Read(1)
+		_, _ = c.Read(3)
+		expected := Stats{Hits: 1, Misses: 1, Writes: 3, Updates: 1, Evictions: 1, Len: 2}
+		if stats := c.Stats(); !reflect.DeepEqual(stats, expected) {
+			t.Fatalf("unexpected stats: %#v", stats)
+		}
+		if rate := c.Stats().HitRate(); rate != 0.5 {
+			t.Fatalf("expected a hit rate of 0.5 but got %f", rate)
+		}
+		c.ResetStats()
+		if stats := c.Stats(); !reflect.DeepEqual(stats, Stats{Len: 2}) {
+			t.Fatalf("expected counters to be zeroed but got %#v", stats)
+		}
+	})
+	t.Run("expired entries are not counted in the length", func(t *testing.T) {
+		clock := newFakeClock()
+		c := Factory[int, int](LRU, 2, WithClock(clock))
+		c.WriteWithTTL(1, 10, time.Second)
+		c.Write(2, 20)
+		clock.advance(time.Minute)
+		expected := Stats{Writes: 2, Evictions: 1, Len: 1}
+		if stats := c.Stats(); !reflect.DeepEqual(stats, expected) || stats.Len != c.Len() {
+			t.Fatalf("unexpected stats %#v with len=%d", stats, c.Len())
+		}
+	})
+	t.Run("expired entries count as misses and evictions", func(t *testing.T) {
+		clock := newFakeClock()
+		c := Factory[int, int](LRU, 2, WithClock(clock))
+		c.WriteWithTTL(1, 10, time.Second)
+		clock.advance(time.Minute)
+		_, _ = c.Read(1)
+		expected := Stats{Misses: 1, Writes: 1, Evictions: 1, Len: 0}
+		if stats := c.Stats(); !reflect.DeepEqual(stats, expected) {
+			t.Fatalf("unexpected stats: %#v", stats)
+		}
+	})
+	t.Run("composite strategies report the occupancy of their segments", func(t *testing.T) {
+		for _, tc := range []struct {
+			algorithm string
+			expected  map[string]int
+		}{
+			{SLRU, map[string]int{"protected": 2, "probation": 1}},
+			{LFRU, map[string]int{"privileged": 2, "unprivileged": 1}},
+			{ARC, map[string]int{"t1": 2, "t2": 2, "b1": 0, "b2": 0, "p": 0}},
+			// Hits only set a reference bit, keys move to t2 when t1's hand reaches them.
+			{CAR, map[string]int{"t1": 4, "t2": 0, "b1": 0, "b2": 0, "p": 0}},
+		} {
+			c := Factory[int, int](tc.algorithm, 4)
+			c.Write(1, 10)
+			c.Write(2, 20)
+			_, _ = c.Read(1)
+			c.Write(3, 30)
+			c.Write(4, 40)
+			c.Write(4, 40)
+			_, _ = c.Read(4)
+			stats := c.Stats()
+			if !reflect.DeepEqual(stats.Segments, tc.expected) {
+				t.Fatalf("%s: unexpected segments %#v", tc.algorithm, stats.Segments)
+			}
+		}
+	})
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" counters are consistent", func(t *testing.T) {
+			var (
+				rnd           = rand.New(rand.NewSource(5))
+				c             = Factory[int, int](algorithm, 8, WithShards(2))
+				reads, writes uint64
+			)
+			for step := 0; step < 5000; step++ {
+				key := rnd.Intn(24)
+				if rnd.Intn(2) == 0 {
+					_, _ = c.Read(key)
+					reads++
+				} else {
+					c.Write(key, key)
+					writes++
+				}
+			}
+			stats := c.Stats()
+			if stats.Hits+stats.Misses != reads || stats.Writes+stats.Updates != writes {
+				t.Fatalf("counters don't add up to %d reads and %d writes: %#v", reads, writes, stats)
+			}
+			if uint64(stats.Len) != stats.Writes-stats.Evictions || stats.Len > 8 {
+				t.Fatalf("length doesn't match the writes and evictions: %#v", stats)
+			}
+			inSegments := stats.Segments["protected"] + stats.Segments["probation"] +
+				stats.Segments["privileged"] + stats.Segments["unprivileged"] +
+				stats.Segments["t1"] + stats.Segments["t2"] +
+				stats.Segments["segment0"] + stats.Segments["segment1"] +
+				stats.Segments["window"] + stats.Segments["a1in"] + stats.Segments["am"] +
+				stats.Segments["lir"] + stats.Segments["hir"] +
+				stats.Segments["hot"] + stats.Segments["cold"] +
+				stats.Segments["small"] + stats.Segments["main"]
+			if stats.Segments != nil && inSegments != stats.Len {
+				t.Fatalf("segments don't add up to the length: %#v", stats)
+			}
+		})
+	}
+	t.Run("counters are not maintained when stats are disabled", func(t *testing.T) {
+		c := Factory[int, int](LRU, 1, WithoutStats())
+		c.Write(1, 10)
+		c.Write(2, 20)
+		_, _ = c.Read(1)
+		_, _ = c.Read(2)
+		if stats := c.Stats(); !reflect.DeepEqual(stats, Stats{Len: 1}) {
+			t.Fatalf("expected only the length to be reported but got %#v", stats)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+func newSynchronized[K comparable, V any](cache Cache[K, V]) *synchronized[K, V] {
+	return &synchronized[K, V]{
+		cache: cache,
+	}
+}
+
+// synchronized implements Cache
+// It guards any other Cache implementation with a mutex. A read-write lock
+// would not be enough because all strategies change their internal state on
+// Read as well, eg. lru promotes the key, lfu bumps its frequency and mru
+// evicts it.
+type synchronized[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache Cache[K, V]
+}
+
+func (s *synchronized[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Read(key)
+}
+
+func (s *synchronized[K, V]) Write(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Write(key, value)
+}
+
+func (s *synchronized[K, V]) WriteWithTTL(key K, value V, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.WriteWithTTL(key, value, ttl)
+}
+
+func (s *synchronized[K, V]) WriteWithCost(key K, value V, cost float64, size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.WriteWithCost(key, value, cost, size)
+}
+
+func (s *synchronized[K, V]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Stats()
+}
+
+func (s *synchronized[K, V]) ResetStats() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.ResetStats()
+}
+
+func (s *synchronized[K, V]) Delete(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Delete(key)
+}
+
+func (s *synchronized[K, V]) Peek(key K) (value V, found bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Peek(key)
+}
+
+func (s *synchronized[K, V]) Contains(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Contains(key)
+}
+
+func (s *synchronized[K, V]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Len()
+}
+
+func (s *synchronized[K, V]) Capacity() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Capacity()
+}
+
+func (s *synchronized[K, V]) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Purge()
+}
+
+func (s *synchronized[K, V]) Resize(size int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Resize(size)
+}
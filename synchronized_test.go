@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSynchronized(t *testing.T) {
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm+" supports concurrent reads and writes", func(t *testing.T) {
+			var (
+				c          = Factory[int, int](algorithm, 64, WithConcurrency())
+				numWorkers = 16
+				numOps     = 2000
+				wg         sync.WaitGroup
+				errs       = make(chan string, numWorkers)
+			)
+			for w := 0; w < numWorkers; w++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					rnd := rand.New(rand.NewSource(seed))
+					for i := 0; i < numOps; i++ {
+						key := rnd.Intn(128)
+						if rnd.Intn(2) == 0 {
+							c.Write(key, key*10)
+							continue
+						}
+						if value, isCacheMiss := c.Read(key); !isCacheMiss && value != key*10 {
+							errs <- "unexpected value read under contention"
+							return
+						}
+					}
+				}(int64(w))
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"math"
+)
+
+const (
+	// defaultKin is the share of the capacity reserved to a1in, as recommended by the paper.
+	defaultKin = 0.25
+	// defaultKout is the number of ghost keys in a1out, relative to the capacity.
+	defaultKout = 0.5
+)
+
+// twoQueue implements Policy
+// It follows the full version of 2Q as described in "2Q: A Low Overhead High
+// Performance Buffer Management Replacement Algorithm" by Theodore Johnson
+// and Dennis Shasha.
+// New keys go through the FIFO queue a1in, and only the keys requested again
+// after their eviction from it, while a1out remembers them, enter the LRU am.
+type twoQueue[K comparable, V any] struct {
+	evictNotifier[K, V]
+	a1in     *lru[K, V]
+	a1out    *lru[K, struct{}]
+	am       *lru[K, V]
+	c        int
+	kin      int
+	inRatio  float64 // kin relative to the capacity
+	outRatio float64 // size of a1out relative to the capacity
+}
+
+func newTwoQueue[K comparable, V any](size int, inRatio, outRatio float64) *twoQueue[K, V] {
+	c := &twoQueue[K, V]{
+		inRatio:  inRatio,
+		outRatio: outRatio,
+	}
+	c.a1in = newLRU[K, V](size)
+	c.am = newLRU[K, V](size)
+	c.a1out = newLRU[K, struct{}](0)
+	c.setSize(size)
+	return c
+}
+
+func (c *twoQueue[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	if node := c.am.read(key); node != nil {
+		return node.value, false
+	}
+	if node, present := c.a1in.hash[key]; present {
+		return node.value, false
+	}
+	return value, true
+}
+
+func (c *twoQueue[K, V]) Write(key K, value V) {
+	if node := c.am.read(key); node != nil {
+		node.value = value
+		return
+	}
+	if node, present := c.a1in.hash[key]; present {
+		node.value = value
+		return
+	}
+	if c.a1out.remove(key) != nil {
+		c.reclaim()
+		c.am.insert(key, value)
+		return
+	}
+	c.reclaim()
+	c.a1in.insert(key, value)
+}
+
+func (c *twoQueue[K, V]) Peek(key K) (value V, found bool) {
+	if value, found = c.am.Peek(key); found {
+		return value, true
+	}
+	return c.a1in.Peek(key)
+}
+
+func (c *twoQueue[K, V]) Delete(key K) bool {
+	if c.a1out.Delete(key) {
+		return false
+	}
+	return c.am.Delete(key) || c.a1in.Delete(key)
+}
+
+func (c *twoQueue[K, V]) Len() int {
+	return c.a1in.Len() + c.am.Len()
+}
+
+func (c *twoQueue[K, V]) Capacity() int {
+	return c.c
+}
+
+func (c *twoQueue[K, V]) Keys() []K {
+	return append(c.am.Keys(), c.a1in.Keys()...)
+}
+
+func (c *twoQueue[K, V]) Purge() {
+	c.a1in.Purge()
+	c.a1out.Purge()
+	c.am.Purge()
+}
+
+// Resize scales kin and a1out to the new size. When shrinking, keys are
+// evicted as usual until they fit.
+func (c *twoQueue[K, V]) Resize(size int) {
+	c.setSize(size)
+	for c.Len() > c.c {
+		c.evict()
+	}
+	_ = c.a1out.resize(c.a1out.size)
+}
+
+// reclaim makes room for a new key, if the cache is full.
+func (c *twoQueue[K, V]) reclaim() {
+	if c.Len() >= c.c {
+		c.evict()
+	}
+}
+
+// evict drops the oldest key of a1in if it holds more than kin keys,
+// remembering it in a1out. Otherwise it drops the least recently used
+// key of am, which is not remembered.
+func (c *twoQueue[K, V]) evict() {
+	if c.a1in.Len() > c.kin || c.am.Len() == 0 {
+		node := c.a1in.remove(c.a1in.last.key)
+		_, _ = c.a1out.write(node.key, struct{}{})
+		c.notifyEvict(node.key, node.value)
+		return
+	}
+	node := c.am.remove(c.am.last.key)
+	c.notifyEvict(node.key, node.value)
+}
+
+// setSize changes the capacity along with kin and the size of a1out,
+// which are both at least one.
+func (c *twoQueue[K, V]) setSize(size int) {
+	c.c = size
+	c.kin = max(1, int(math.Round(float64(size)*c.inRatio)))
+	c.a1in.size, c.am.size = size, size
+	c.a1out.size = max(1, int(math.Round(float64(size)*c.outRatio)))
+}
+
+type twoQueueState[K comparable] struct {
+	a1in, a1out, am []K // keys from newest to oldest
+}
+
+func (c *twoQueue[K, V]) state() twoQueueState[K] {
+	return twoQueueState[K]{
+		a1in:  c.a1in.Keys(),
+		a1out: c.a1out.Keys(),
+		am:    c.am.Keys(),
+	}
+}
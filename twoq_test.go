@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTwoQueue(t *testing.T) {
+	type step struct {
+		write    bool
+		key      int
+		hit      bool // only checked for reads
+		expected twoQueueState[int]
+		evicted  []int // keys evicted by the step
+	}
+	for _, tc := range []struct {
+		name      string
+		size      int
+		kin, kout float64
+		steps     []step
+	}{
+		{
+			name: "keys move from a1in to a1out and back into am",
+			size: 4, kin: defaultKin, kout: defaultKout, // kin=1, kout=2
+			steps: []step{
+				{write: true, key: 1, expected: twoQueueState[int]{a1in: []int{1}}},
+				{write: true, key: 2, expected: twoQueueState[int]{a1in: []int{2, 1}}},
+				{write: true, key: 3, expected: twoQueueState[int]{a1in: []int{3, 2, 1}}},
+				{write: true, key: 4, expected: twoQueueState[int]{a1in: []int{4, 3, 2, 1}}},
+				// a1in is a FIFO, requests don't move keys.
+				{key: 1, hit: true, expected: twoQueueState[int]{a1in: []int{4, 3, 2, 1}}},
+				{write: true, key: 5, evicted: []int{1},
+					expected: twoQueueState[int]{a1in: []int{5, 4, 3, 2}, a1out: []int{1}}},
+				{write: true, key: 6, evicted: []int{2},
+					expected: twoQueueState[int]{a1in: []int{6, 5, 4, 3}, a1out: []int{2, 1}}},
+				// Ghost hit, the key is admitted into am.
+				{key: 1, expected: twoQueueState[int]{a1in: []int{6, 5, 4, 3}, a1out: []int{2, 1}}},
+				{write: true, key: 1, evicted: []int{3},
+					expected: twoQueueState[int]{a1in: []int{6, 5, 4}, a1out: []int{3, 2}, am: []int{1}}},
+				// a1out only remembers kout keys.
+				{write: true, key: 7, evicted: []int{4},
+					expected: twoQueueState[int]{a1in: []int{7, 6, 5}, a1out: []int{4, 3}, am: []int{1}}},
+				{key: 2, expected: twoQueueState[int]{a1in: []int{7, 6, 5}, a1out: []int{4, 3}, am: []int{1}}},
+				{write: true, key: 3, evicted: []int{5},
+					expected: twoQueueState[int]{a1in: []int{7, 6}, a1out: []int{5, 4}, am: []int{3, 1}}},
+				{write: true, key: 4, evicted: []int{6},
+					expected: twoQueueState[int]{a1in: []int{7}, a1out: []int{6, 5}, am: []int{4, 3, 1}}},
+				// a1in is down to kin keys, so am evicts its least recently used key, which is not remembered.
+				{write: true, key: 8, evicted: []int{1},
+					expected: twoQueueState[int]{a1in: []int{8, 7}, a1out: []int{6, 5}, am: []int{4, 3}}},
+				{key: 3, hit: true,
+					expected: twoQueueState[int]{a1in: []int{8, 7}, a1out: []int{6, 5}, am: []int{3, 4}}},
+				{write: true, key: 9, evicted: []int{7},
+					expected: twoQueueState[int]{a1in: []int{9, 8}, a1out: []int{7, 6}, am: []int{3, 4}}},
+			},
+		},
+		{
+			name: "a larger kin protects keys requested once from am",
+			size: 4, kin: 0.5, kout: 1, // kin=2, kout=4
+			steps: []step{
+				{write: true, key: 1, expected: twoQueueState[int]{a1in: []int{1}}},
+				{write: true, key: 2, expected: twoQueueState[int]{a1in: []int{2, 1}}},
+				{write: true, key: 3, expected: twoQueueState[int]{a1in: []int{3, 2, 1}}},
+				{write: true, key: 1, expected: twoQueueState[int]{a1in: []int{3, 2, 1}}},
+				{write: true, key: 4, expected: twoQueueState[int]{a1in: []int{4, 3, 2, 1}}},
+				{write: true, key: 5, evicted: []int{1},
+					expected: twoQueueState[int]{a1in: []int{5, 4, 3, 2}, a1out: []int{1}}},
+				{write: true, key: 1, evicted: []int{2},
+					expected: twoQueueState[int]{a1in: []int{5, 4, 3}, a1out: []int{2}, am: []int{1}}},
+				{write: true, key: 2, evicted: []int{3},
+					expected: twoQueueState[int]{a1in: []int{5, 4}, a1out: []int{3}, am: []int{2, 1}}},
+				// a1in holds kin keys, so am gives up its least recently used key.
+				{write: true, key: 6, evicted: []int{1},
+					expected: twoQueueState[int]{a1in: []int{6, 5, 4}, a1out: []int{3}, am: []int{2}}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTwoQueue[int, int](tc.size, tc.kin, tc.kout)
+			evicted := []int{}
+			c.SetEvictHandler(func(key, value int) {
+				evicted = append(evicted, key)
+			})
+			for i, step := range tc.steps {
+				evicted = evicted[:0]
+				if step.write {
+					c.Write(step.key, step.key*10)
+				} else if value, isCacheMiss := c.Read(step.key); isCacheMiss == step.hit || (step.hit && value != step.key*10) {
+					t.Fatalf("step %d: unexpected read of key %d: value=%d, isCacheMiss=%t", i, step.key, value, isCacheMiss)
+				}
+				if state := c.state(); !equalStates(state, step.expected) {
+					t.Fatalf("step %d: expected state %#v but got %#v", i, step.expected, state)
+				}
+				if len(evicted) != len(step.evicted) || (len(evicted) > 0 && !reflect.DeepEqual(evicted, step.evicted)) {
+					t.Fatalf("step %d: expected evictions %#v but got %#v", i, step.evicted, evicted)
+				}
+			}
+		})
+	}
+	t.Run("deleted keys are forgotten by a1out", func(t *testing.T) {
+		c := newTwoQueue[int, int](2, defaultKin, defaultKout)
+		c.Write(1, 10)
+		c.Write(2, 20)
+		c.Write(3, 30) // 1 is in a1out
+		if c.Delete(1) || !c.Delete(2) || c.Delete(2) {
+			t.Fatalf("unexpected deletes, state %#v", c.state())
+		}
+		c.Write(1, 10)
+		if state := c.state(); !equalStates(state, twoQueueState[int]{a1in: []int{1, 3}}) {
+			t.Fatalf("expected key 1 to be written in a1in again but got %#v", state)
+		}
+	})
+	t.Run("shrinking evicts keys as usual and scales the thresholds", func(t *testing.T) {
+		c := newTwoQueue[int, int](8, defaultKin, defaultKout) // kin=2, kout=4
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		for key := 1; key <= 8; key++ {
+			c.Write(key, key*10)
+		}
+		c.Write(9, 90) // a1in (9..2), a1out (1)
+		c.Write(1, 10) // a1in (9..3), a1out (2), am (1)
+		c.Resize(4)    // kin=1, kout=2
+		expected := twoQueueState[int]{a1in: []int{9, 8, 7}, a1out: []int{6, 5}, am: []int{1}}
+		if state := c.state(); !equalStates(state, expected) || c.kin != 1 || c.Capacity() != 4 {
+			t.Fatalf("unexpected state %#v after shrinking", state)
+		}
+		if !reflect.DeepEqual(evicted, []int{1, 2, 3, 4, 5, 6}) {
+			t.Fatalf("unexpected evictions %#v", evicted)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+const (
+	// wTinyLFUWindowRatio is the share of the capacity given to the window.
+	wTinyLFUWindowRatio = 0.01
+	// wTinyLFUProtectedRatio is the share of the main region given to protected.
+	wTinyLFUProtectedRatio = 0.8
+)
+
+// wTinyLFU implements Policy
+// New keys enter a small lru window. Keys evicted from the window are
+// candidates for the main region, an slru. When the main region is full,
+// a candidate is only admitted if it was requested more often than the key
+// the main region would evict, according to a count-min sketch. This keeps
+// keys requested only once, eg. by scans, from flushing popular keys.
+// See https://arxiv.org/abs/1512.00727
+type wTinyLFU[K comparable, V any] struct {
+	evictNotifier[K, V]
+	window *lru[K, V]
+	main   *slru[K, V]
+	sketch *countMinSketch
+	seed   maphash.Seed
+}
+
+func newWTinyLFU[K comparable, V any](size int) *wTinyLFU[K, V] {
+	window, main := splitWTinyLFU(size)
+	c := &wTinyLFU[K, V]{
+		window: newLRU[K, V](window),
+		main:   newSLRU[K, V](main, wTinyLFUProtectedRatio),
+		sketch: newCountMinSketch(size),
+		seed:   maphash.MakeSeed(),
+	}
+	// Keys evicted from the main region leave the cache.
+	c.main.SetEvictHandler(c.notifyEvict)
+	return c
+}
+
+func (c *wTinyLFU[K, V]) Read(key K) (value V, isCacheMiss bool) {
+	if node := c.window.read(key); node != nil {
+		c.record(key)
+		return node.value, false
+	}
+	if value, isCacheMiss = c.main.Read(key); !isCacheMiss {
+		c.record(key)
+	}
+	return value, isCacheMiss
+}
+
+// Write only records requests for keys which are not in the cache, the
+// requests for the other keys are recorded by Read. This way a read hit
+// followed by an update counts once, like a read miss followed by a write.
+func (c *wTinyLFU[K, V]) Write(key K, value V) {
+	if node := c.window.read(key); node != nil {
+		node.value = value
+		return
+	}
+	if _, found := c.main.Peek(key); found {
+		c.main.Write(key, value)
+		return
+	}
+	c.record(key)
+	if _, evicted := c.window.write(key, value); evicted != nil {
+		c.admit(evicted.key, evicted.value)
+	}
+}
+
+func (c *wTinyLFU[K, V]) Peek(key K) (value V, found bool) {
+	if value, found = c.window.Peek(key); found {
+		return value, true
+	}
+	return c.main.Peek(key)
+}
+
+func (c *wTinyLFU[K, V]) Delete(key K) bool {
+	return c.window.Delete(key) || c.main.Delete(key)
+}
+
+func (c *wTinyLFU[K, V]) Len() int {
+	return c.window.Len() + c.main.Len()
+}
+
+func (c *wTinyLFU[K, V]) Capacity() int {
+	return c.window.Capacity() + c.main.Capacity()
+}
+
+func (c *wTinyLFU[K, V]) Keys() []K {
+	return append(c.window.Keys(), c.main.Keys()...)
+}
+
+// Purge also forgets the frequencies of the keys.
+func (c *wTinyLFU[K, V]) Purge() {
+	c.window.Purge()
+	c.main.Purge()
+	c.sketch.reset()
+}
+
+// Resize splits the new size between the window and the main region. When
+// shrinking, the main region evicts whatever doesn't fit in anymore, then
+// the overflow of the window competes for admission into the main region.
+func (c *wTinyLFU[K, V]) Resize(size int) {
+	window, main := splitWTinyLFU(size)
+	c.main.Resize(main)
+	for _, node := range c.window.resize(window) {
+		c.admit(node.key, node.value)
+	}
+	c.sketch.resize(size)
+}
+
+// admit moves a key evicted from the window into the main region if there is
+// room in probation or if it's more popular than the victim of probation.
+// Otherwise the key leaves the cache.
+func (c *wTinyLFU[K, V]) admit(key K, value V) {
+	probation := c.main.probation
+	if probation.Len() < probation.Capacity() ||
+		c.sketch.estimate(c.hash(key)) > c.sketch.estimate(c.hash(probation.last.key)) {
+		// The victim of probation, if any, is reported by the main region.
+		c.main.demote(key, value)
+		return
+	}
+	c.notifyEvict(key, value)
+}
+
+// record counts a request for the key in the sketch.
+func (c *wTinyLFU[K, V]) record(key K) {
+	c.sketch.increment(c.hash(key))
+}
+
+func (c *wTinyLFU[K, V]) hash(key K) uint64 {
+	return maphash.Comparable(c.seed, key)
+}
+
+// splitWTinyLFU returns the sizes of the window and of the main region.
+func splitWTinyLFU(size int) (window, main int) {
+	window = max(1, int(math.Round(float64(size)*wTinyLFUWindowRatio)))
+	return window, size - window
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWTinyLFU(t *testing.T) {
+	t.Run("capacity is split between the window and the main region", func(t *testing.T) {
+		c := newWTinyLFU[int, int](1000)
+		if c.window.size != 10 || c.main.protected.size != 792 || c.main.probation.size != 198 {
+			t.Fatalf("unexpected sizes window=%d, protected=%d, probation=%d",
+				c.window.size, c.main.protected.size, c.main.probation.size)
+		}
+	})
+	t.Run("candidates from the window are admitted only if more popular than the victim", func(t *testing.T) {
+		c := newWTinyLFU[int, int](4) // window=1, protected=2, probation=1
+		evicted := []int{}
+		c.SetEvictHandler(func(key, value int) {
+			evicted = append(evicted, key)
+		})
+		c.Write(1, 10) // (1); (_, _); (_)
+		c.Write(2, 20) // (2); (_, _); (1)
+		c.Write(3, 30) // (3); (_, _); (1), 2 is as popular as 1 so it's rejected
+		if !reflect.DeepEqual(evicted, []int{2}) || c.window.head.key != 3 || c.main.probation.head.key != 1 {
+			t.Fatalf("expected key 2 to be rejected but got evictions %#v", evicted)
+		}
+		_, _ = c.Read(3)
+		_, _ = c.Read(3)
+		c.Write(4, 40) // (4); (_, _); (3), 3 was requested more often than 1
+		if !reflect.DeepEqual(evicted, []int{2, 1}) || c.window.head.key != 4 || c.main.probation.head.key != 3 {
+			t.Fatalf("expected key 3 to be admitted but got evictions %#v", evicted)
+		}
+		_, _ = c.Read(3) // (4); (3, _); (_)
+		if c.main.protected.head.key != 3 || c.main.probation.Len() != 0 {
+			t.Fatalf("expected key 3 to be promoted to protected: %#v", c.main.protected.state())
+		}
+	})
+	t.Run("a read hit followed by an update counts as one request", func(t *testing.T) {
+		c := newWTinyLFU[int, int](100)
+		c.Write(1, 10)
+		_, _ = c.Read(1)
+		before := c.sketch.estimate(c.hash(1))
+		c.Write(1, 11)
+		if after := c.sketch.estimate(c.hash(1)); after != before {
+			t.Fatalf("expected the update not to change the estimate of %d but got %d", before, after)
+		}
+	})
+	t.Run("popular keys survive a scan", func(t *testing.T) {
+		c := newWTinyLFU[int, int](100)
+		for round := 0; round < 5; round++ {
+			for key := 0; key < 20; key++ {
+				if _, isCacheMiss := c.Read(key); isCacheMiss {
+					c.Write(key, key)
+				}
+			}
+		}
+		for key := 1000; key < 2000; key++ {
+			if _, isCacheMiss := c.Read(key); isCacheMiss {
+				c.Write(key, key)
+			}
+		}
+		for key := 0; key < 20; key++ {
+			if _, found := c.Peek(key); !found {
+				t.Fatalf("expected popular key %d to survive the scan", key)
+			}
+		}
+	})
+	t.Run("hit rate beats lru on a skewed workload with scans", func(t *testing.T) {
+		expectHigherHitRate(t, skewedTrace(8, 10000, 100000, true), 200, WTinyLFU, LRU)
+	})
+}